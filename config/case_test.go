@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type caseConfig struct {
+	DB struct {
+		Port     int    `mapstructure:"Port"`
+		PortLow  int    `mapstructure:"port"`
+		User     string `mapstructure:"user" default:"root"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"DB"`
+	Labels map[string]string `mapstructure:"labels"`
+}
+
+func TestReadConfigCaseSensitive(t *testing.T) {
+	files := map[string]string{
+		"case.cfg":        "[DB]\nPort = 3306\nport = 3307\n\n[labels]\nApp = a\napp = b\n",
+		"case.ini":        "[DB]\nPort = 3306\nport = 3307\n\n[labels]\nApp = a\napp = b\n",
+		"case.yaml":       "DB:\n  Port: 3306\n  port: 3307\nlabels:\n  App: a\n  app: b\n",
+		"case.json":       `{"DB": {"Port": 3306, "port": 3307}, "labels": {"App": "a", "app": "b"}}`,
+		"case.toml":       "[DB]\nPort = 3306\nport = 3307\n[labels]\nApp = \"a\"\napp = \"b\"\n",
+		"case.properties": "DB.Port = 3306\nDB.port = 3307\nlabels.App = a\nlabels.app = b\n",
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		writeFile(t, filename, content)
+
+		var c caseConfig
+		v, err := ReadConfig(&c, filename, WithCaseSensitive(), WithStrict())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// viper 中的 key 不区分大小写，Port 和 port 保留按字节排序的第一个 Port
+		if got := v.GetInt(strings.Join([]string{"db", "port"}, keyDelimiter(strings.TrimPrefix(filepath.Ext(name), ".")))); got != 3306 {
+			t.Fatalf("%s: viper db port = %d, want 3306", name, got)
+		}
+		if c.DB.Port != 3306 || c.DB.PortLow != 3307 || c.DB.User != "root" {
+			t.Fatalf("%s: unexpected config %+v", name, c.DB)
+		}
+		if len(c.Labels) != 2 || c.Labels["App"] != "a" || c.Labels["app"] != "b" {
+			t.Fatalf("%s: unexpected labels %v", name, c.Labels)
+		}
+	}
+}
+
+func TestReadConfigCaseSensitiveEnv(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "case.env")
+	writeFile(t, filename, "DB_HOST=localhost\ndb_host=127.0.0.1\n")
+
+	var c struct {
+		Upper string `mapstructure:"DB_HOST"`
+		Lower string `mapstructure:"db_host"`
+	}
+	if _, err := ReadConfig(&c, filename, WithCaseSensitive()); err != nil {
+		t.Fatal(err)
+	}
+	if c.Upper != "localhost" || c.Lower != "127.0.0.1" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	t.Setenv("APP_DB__PASSWORD", "s3cret")
+	yaml := filepath.Join(t.TempDir(), "case.yaml")
+	writeFile(t, yaml, "DB:\n  password: x\n")
+	var cc caseConfig
+	if _, err := ReadConfig(&cc, yaml, WithCaseSensitive(), WithEnvPrefix("APP")); err != nil {
+		t.Fatal(err)
+	}
+	if cc.DB.Password != "s3cret" {
+		t.Fatalf("unexpected config %+v", cc.DB)
+	}
+
+	writeFile(t, yaml, "db:\n  port: 1\n")
+	if _, err := ReadConfig(&cc, yaml, WithCaseSensitive(), WithStrict()); !errors.Is(err, ErrStrict) {
+		t.Fatalf("err = %v, want ErrStrict", err)
+	}
+}
+
+func TestReadConfigCaseSensitiveViper(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "case.cfg")
+	writeFile(t, filename, "[DB]\nPort = 3306\nuser = admin\n")
+
+	var c caseConfig
+	v, err := ReadConfig(&c, filename, WithCaseSensitive())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DB.Port != 3306 || c.DB.PortLow != 0 || c.DB.User != "admin" {
+		t.Fatalf("unexpected config %+v", c.DB)
+	}
+	if v == nil || v.GetInt("db::port") != 3306 || v.GetString("db::user") != "admin" {
+		t.Fatalf("unexpected viper %v", v)
+	}
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"bytes"
 	"errors"
+	"hash"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +15,9 @@ var (
 	ErrFileTypeNotAllow = errors.New("file type not allow")
 	ErrReadInConfig     = errors.New("fatal error config file")
 	ErrUnmarshal        = errors.New("unmarshal config failed")
+	ErrValidate         = errors.New("validate config failed")
+	ErrResolve          = errors.New("resolve config reference failed")
+	ErrStrict           = errors.New("config keys mismatch")
 )
 
 func allowType(t string) bool {
@@ -42,46 +47,209 @@ func allowType(t string) bool {
 }
 
 // ReadConfig 使用 viper 读取配置文件 支持文件类型 JSON, TOML, YAML, HCL, INI, envfile or Java properties
-// viper的配置的key值目前是不区分大小写, 如果文件后缀为 cfg 格式则这里采用默认的分割符为 ::
-func ReadConfig(config any, filename string) (v *viper.Viper, err error) {
+// viper的配置的key值目前是不区分大小写(WithCaseSensitive 开启后区分, 但返回的 v 中的 key 仍然不区分大小写), 如果文件后缀为 cfg 格式则这里采用默认的分割符为 ::
+// 结构体字段上 default tag 声明的默认值会在解析前生效，详见 applyDefaults
+// 值中 ${env:NAME}, ${file:/path} 形式的引用会在解析前替换，详见 WithResolver
+// 值中 ENC[AES256-GCM,...] 形式的加密内容会在解析前解密，详见 WithKeyFile
+// opts 可以设置文件格式, 分割符, 环境变量以及命令行参数覆盖, 严格模式, profile 等选项，
+// 详见 WithFormat, WithDelimiter, WithEnvPrefix, WithFlags, WithStrict, WithProfile;
+// 解析完成后会按照 validate tag 校验配置，详见 Validate
+func ReadConfig(config any, filename string, opts ...Option) (*viper.Viper, error) {
+	return readConfig(config, filename, nil, opts)
+}
+
+// readConfig 与 ReadConfig 一致，h 不为空时写入读取的文件内容(包括 include 的文件)
+func readConfig(config any, filename string, h hash.Hash, opts []Option) (v *viper.Viper, err error) {
 	if _, err = os.Stat(filename); err != nil {
 		return nil, err
 	}
 
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	o := newOptions(opts)
+
+	fileType, err := o.configType(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err = read(config, data, fileType, &includer{name: filename, hash: h}, o)
+	if err != nil {
+		return nil, err
+	}
+	v.SetConfigFile(filename)
+
+	return v, nil
+}
+
+// read 解析配置内容到 config 上，ReadConfig, ReadConfigFrom, ReadConfigFS 共用
+func read(config any, data []byte, fileType string, inc *includer, o *options) (*viper.Viper, error) {
+	inc.digest(data)
+	delim := o.keyDelimiter(fileType)
+
+	var (
+		v     store
+		err   error
+		match keyMatch = foldMatch
+	)
+	if o.caseSensitive {
+		v, err = parseCase(data, fileType, delim, inc, o.resolvers)
+		match = exactMatch
+	} else {
+		v, err = parse(data, fileType, delim, inc, o.resolvers)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if v, err = o.applyProfile(v, delim, fileType); err != nil {
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	if o.strict {
+		if err := checkStrict(v.AllSettings(), config, delim, o.strictFields, match); err != nil {
+			return nil, err
+		}
+	}
+
+	applyDefaults(v, config, delim, match)
+
+	if err := interpolate(v, o.resolvers); err != nil {
+		return nil, err
+	}
+
+	o.applyEnv(v, delim)
+
+	o.applyFlags(v, config, delim)
+
+	if err := o.decrypt(v); err != nil {
+		return nil, err
+	}
+
+	vp, err := unmarshalStore(v, config, delim)
+	if err != nil {
+		return nil, errors.Join(ErrUnmarshal, err)
+	}
+
+	if err := validate(config, delim); err != nil {
+		return nil, err
+	}
+
+	return vp, nil
+}
+
+// extTypes 文件后缀无法直接表示文件类型时的映射关系
+var extTypes = map[string]string{
+	// 如果文件名称不包含后缀这里为了兼容 atlantis-agent
+	// 写到配置文件目录下的 .atlantis 文件(内容格式为 cfg)
+	// 这里默认设置为 cfg 格式
+	".atlantis": "cfg",
+}
+
+// configType 根据文件名称获取配置文件的类型，文件没有后缀或者后缀不是支持的类型时根据文件内容 data 判断，
+// 例如挂载的 ConfigMap /etc/app/config
+func configType(filename string, data []byte) (string, error) {
+	if fileType := extType(filename); fileType != "" {
+		return fileType, nil
+	}
+
+	fileType := sniffType(data)
+	if fileType == "" {
+		return "", ErrFileTypeNotAllow
+	}
+
+	return fileType, nil
+}
+
+// DetectFormat 获取配置文件的类型，规则与 ReadConfig 一致，例如 .atlantis 文件为 cfg
+func DetectFormat(filename string, data []byte) (string, error) {
+	return configType(filename, data)
+}
+
+// extType 根据文件后缀获取配置文件的类型，后缀不是支持的类型时返回空字符串
+func extType(filename string) string {
 	ext := filepath.Ext(filename)
-	fileType := ""
-	if ext != ".atlantis" { // 这里说名文件名称包含后缀
+	fileType, ok := extTypes[ext]
+	if !ok && ext != "" { // 这里说名文件名称包含后缀
 		fileType = ext[1:] // 注意这里获取到的文件后缀是包含 . 号的，再进行和 allowType 进行比较的时候需要去除开头的
-	} else {
-		// 如果文件名称不包含后缀这里为了兼容 atlantis-agent
-		// 写到配置文件目录下的 .atlantis 文件(内容格式为 cfg)
-		// 这里默认设置为 cfg 格式
-		fileType = "cfg"
 	}
 
 	fileType = strings.ToLower(fileType)
 	if !allowType(fileType) {
-		return nil, ErrFileTypeNotAllow
+		return ""
 	}
 
-	v = viper.NewWithOptions()
+	return fileType
+}
+
+// keyDelimiter 获取文件类型对应的 key 分割符
+// KeyDelimiter 返回 format 对应的默认 key 分割符，cfg 为 ::，其它格式为 .
+func KeyDelimiter(format string) string {
+	return keyDelimiter(strings.ToLower(format))
+}
 
+func keyDelimiter(fileType string) string {
 	if fileType == "cfg" {
-		fileType = "ini"
 		// 如果你想要解析那些键本身就包含.(默认的键分隔符）的配置，需要修改分隔符, 这里默认设置为 ::
-		v = viper.NewWithOptions(viper.KeyDelimiter("::"))
+		return "::"
 	}
 
-	v.SetConfigFile(filename)
+	return "."
+}
+
+// newViper 根据文件类型创建 viper 实例，cfg 格式转为 ini 进行解析
+func newViper(fileType, delim string) *viper.Viper {
+	v := viper.NewWithOptions(viper.KeyDelimiter(delim))
+
+	if fileType == "cfg" {
+		fileType = "ini"
+	}
 	v.SetConfigType(fileType)
 
-	if err := v.ReadInConfig(); err != nil {
-		return nil, errors.Join(ErrReadInConfig, err)
+	return v
+}
+
+// preprocess 展开 ini/cfg 格式内容中的 include 指令，保护 properties 格式内容中 ${scheme:ref} 形式的引用
+func preprocess(data []byte, fileType string, inc *includer, resolvers map[string]Resolver) ([]byte, error) {
+	switch fileType {
+	case "ini", "cfg":
+		return inc.expandIncludes(data)
+	case "properties", "props", "prop":
+		return protectRefs(data, resolvers), nil
 	}
 
-	if err := v.Unmarshal(config); err != nil {
-		return nil, errors.Join(ErrUnmarshal, err)
+	return data, nil
+}
+
+// parse 使用 viper 解析配置内容，详见 preprocess
+func parse(data []byte, fileType, delim string, inc *includer, resolvers map[string]Resolver) (*viper.Viper, error) {
+	data, err := preprocess(data, fileType, inc, resolvers)
+	if err != nil {
+		return nil, err
+	}
+
+	v := newViper(fileType, delim)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, errors.Join(ErrReadInConfig, err)
 	}
 
 	return v, nil
 }
+
+// parseCase 与 parse 一致，解析时保留 key 原始的大小写
+func parseCase(data []byte, fileType, delim string, inc *includer, resolvers map[string]Resolver) (*caseStore, error) {
+	data, err := preprocess(data, fileType, inc, resolvers)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := decodeCase(data, fileType, delim)
+	if err != nil {
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	return newCaseStore(m, delim), nil
+}
@@ -0,0 +1,202 @@
+// cfgcheck 检查配置文件能否被 config 包正确解析，输出所有展开后的 key、解析后的值以及来源文件，
+// ini/cfg 文件中同一个 section 下重复的 key 会输出警告，解析失败或者存在重复的 key 时退出码为 1
+//
+//	cfgcheck server.cfg .atlantis
+//	cfgcheck -layered base.yaml prod.cfg   # 按顺序合并后输出
+//	cfgcheck -case-sensitive server.cfg    # 与 WithCaseSensitive 一致，Port 和 port 为不同的 key
+//
+// 名称中包含 password, secret, token 以及以 key 结尾的值默认输出为 ******，可以使用 -show-secrets 输出原值
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pemako/gopkg/config"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+type checker struct {
+	quiet         bool
+	showSecrets   bool
+	caseSensitive bool
+	stdout        io.Writer
+	stderr        io.Writer
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cfgcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	layered := fs.Bool("layered", false, "merge all files in order and check the result")
+	quiet := fs.Bool("q", false, "only report errors")
+	showSecrets := fs.Bool("show-secrets", false, "print secret values instead of ******")
+	caseSensitive := fs.Bool("case-sensitive", false, "keep the case of keys, Port and port are different keys")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: cfgcheck [-layered | -case-sensitive] [-q] [-show-secrets] files...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 || (*layered && *caseSensitive) {
+		fs.Usage()
+		return 2
+	}
+
+	c := &checker{quiet: *quiet, showSecrets: *showSecrets, caseSensitive: *caseSensitive, stdout: stdout, stderr: stderr}
+
+	ok := true
+	for _, filename := range fs.Args() {
+		ok = c.duplicates(filename) && ok
+	}
+
+	if *layered {
+		return exitCode(c.check(fs.Args()...) && ok)
+	}
+	for _, filename := range fs.Args() {
+		ok = c.check(filename) && ok
+	}
+
+	return exitCode(ok)
+}
+
+func exitCode(ok bool) int {
+	if ok {
+		return 0
+	}
+
+	return 1
+}
+
+// check 解析 files 并输出所有的 key
+func (c *checker) check(files ...string) bool {
+	name := strings.Join(files, " + ")
+
+	var (
+		values  map[string]string
+		sources map[string]string
+		err     error
+	)
+	if c.caseSensitive {
+		values, sources, err = readCase(files[0])
+	} else {
+		values, sources, err = readLayered(files...)
+	}
+	if err != nil {
+		fmt.Fprintf(c.stderr, "%s: %v\n", name, err)
+		return false
+	}
+
+	if c.quiet {
+		return true
+	}
+
+	keys := make([]string, 0, len(sources))
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(c.stdout, "==> %s\n", name)
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, key := range keys {
+		value := values[key]
+		if !c.showSecrets && config.IsSecretKey(key) {
+			value = "******"
+		}
+		fmt.Fprintf(w, "%s\t= %s\t%s\n", key, value, sources[key])
+	}
+	w.Flush()
+
+	return true
+}
+
+// readLayered 按顺序合并 files，返回每个 key 的值以及来源文件
+func readLayered(files ...string) (values, sources map[string]string, err error) {
+	var settings map[string]any
+	v, sources, err := config.ReadLayered(&settings, files...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values = make(map[string]string, len(sources))
+	for key := range sources {
+		values[key] = fmt.Sprint(v.Get(key))
+	}
+
+	return values, sources, nil
+}
+
+// readCase 区分大小写读取 filename，viper 中的 key 不区分大小写，因此直接展开解析得到的 map
+func readCase(filename string) (values, sources map[string]string, err error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	format, err := config.DetectFormat(filename, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	delim := config.KeyDelimiter(format)
+
+	var settings map[string]any
+	if _, err := config.ReadConfig(&settings, filename, config.WithCaseSensitive()); err != nil {
+		return nil, nil, err
+	}
+
+	values = make(map[string]string)
+	flatten(settings, "", delim, values)
+	sources = make(map[string]string, len(values))
+	for key := range values {
+		sources[key] = filename
+	}
+
+	return values, sources, nil
+}
+
+func flatten(m map[string]any, prefix, delim string, out map[string]string) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + delim + k
+		}
+
+		if sub, ok := v.(map[string]any); ok {
+			flatten(sub, key, delim, out)
+			continue
+		}
+		out[key] = fmt.Sprint(v)
+	}
+}
+
+// duplicates 检查 ini/cfg 文件中重复的 key
+func (c *checker) duplicates(filename string) bool {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		// 读取失败的错误在 check 中输出
+		return true
+	}
+
+	format, err := config.DetectFormat(filename, data)
+	if err != nil || (format != "ini" && format != "cfg") {
+		return true
+	}
+
+	dups := config.DuplicateKeys(data)
+	if c.caseSensitive {
+		dups = config.DuplicateKeysCaseSensitive(data)
+	}
+	for _, d := range dups {
+		fmt.Fprintf(c.stderr, "%s: %s\n", filename, d)
+	}
+
+	return len(dups) == 0
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	writeFile(t, base, "server:\n  name: base\n  http_port: 8080\ndb:\n  password: s3cret\n")
+	atlantis := filepath.Join(dir, ".atlantis")
+	writeFile(t, atlantis, "[server]\nhttp_port = 10000\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-layered", base, atlantis}, &stdout, &stderr); code != 0 {
+		t.Fatalf("code = %d, stderr = %s", code, stderr.String())
+	}
+	got := make(map[string]string)
+	for _, line := range strings.Split(stdout.String(), "\n")[1:] {
+		if fields := strings.Fields(line); len(fields) == 4 {
+			got[fields[0]] = fields[2] + " " + fields[3]
+		}
+	}
+	want := map[string]string{
+		"server::http_port": "10000 " + atlantis,
+		"server::name":      "base " + base,
+		"db::password":      "****** " + base,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+
+	dup := filepath.Join(dir, "dup.cfg")
+	writeFile(t, dup, "[server]\nname = a\nname = b\n")
+	broken := filepath.Join(dir, "broken.json")
+	writeFile(t, broken, "{")
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-q", dup, broken}, &stdout, &stderr); code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), `duplicate key "name"`) || !strings.Contains(stderr.String(), "broken.json") {
+		t.Fatalf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestRunCaseSensitive(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "case.cfg")
+	writeFile(t, filename, "[DB]\nPort = 3306\nport = 3307\nPassword = s3cret\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{filename}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), `duplicate key "port"`) {
+		t.Fatalf("code = %d, stderr = %q", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-case-sensitive", filename}, &stdout, &stderr); code != 0 {
+		t.Fatalf("code = %d, stderr = %s", code, stderr.String())
+	}
+	got := make(map[string]string)
+	for _, line := range strings.Split(stdout.String(), "\n")[1:] {
+		if fields := strings.Fields(line); len(fields) == 4 {
+			got[fields[0]] = fields[2]
+		}
+	}
+	want := map[string]string{"DB::Port": "3306", "DB::port": "3307", "DB::Password": "******"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+
+	if code := run([]string{"-case-sensitive", "-layered", filename}, &stdout, &stderr); code != 2 {
+		t.Fatalf("code = %d, want 2", code)
+	}
+}
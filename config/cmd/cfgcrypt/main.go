@@ -0,0 +1,100 @@
+// cfgcrypt 加密或解密配置文件中的值，只替换文本不解析格式，因此适用于所有支持的格式
+//
+//	cfgcrypt genkey > config.key
+//	cfgcrypt encrypt -key-file config.key server.cfg   # DEC[明文] => ENC[AES256-GCM,...]
+//	cfgcrypt decrypt -key-file config.key server.cfg   # ENC[AES256-GCM,...] => DEC[明文]
+//
+// 没有指定文件时从标准输入读取并输出到标准输出，没有指定 -key-file 时依次读取 CONFIG_KEY 和 CONFIG_KEY_FILE 环境变量
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pemako/gopkg/config"
+	"github.com/pemako/gopkg/config/internal/fileutil"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "cfgcrypt:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() error {
+	return errors.New("usage: cfgcrypt genkey | encrypt [-key-file file] [files...] | decrypt [-key-file file] [files...]")
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return usage()
+	}
+
+	var transform func(key, data []byte) ([]byte, error)
+	switch args[0] {
+	case "genkey":
+		key, err := config.GenerateKey()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, key)
+		return err
+	case "encrypt":
+		transform = config.EncryptValues
+	case "decrypt":
+		transform = config.DecryptValues
+	default:
+		return usage()
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	keyFile := fs.String("key-file", "", "key file generated by cfgcrypt genkey")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	key, err := config.LoadKey(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		if data, err = transform(key, data); err != nil {
+			return err
+		}
+		_, err = stdout.Write(data)
+		return err
+	}
+
+	for _, filename := range fs.Args() {
+		if err := rewrite(filename, func(data []byte) ([]byte, error) {
+			return transform(key, data)
+		}); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+// rewrite 使用 fn 转换文件内容后原子替换原文件
+func rewrite(filename string, fn func([]byte) ([]byte, error)) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if data, err = fn(data); err != nil {
+		return err
+	}
+
+	return fileutil.WriteFile(filename, data)
+}
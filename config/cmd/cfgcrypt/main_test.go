@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	var key bytes.Buffer
+	if err := run([]string{"genkey"}, nil, &key); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "config.key")
+	if err := os.WriteFile(keyFile, key.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "server.yaml")
+	content := "db:\n  password: DEC[s3cret]\n"
+	if err := os.WriteFile(filename, []byte(content), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run([]string{"encrypt", "-key-file", keyFile, filename}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "ENC[AES256-GCM,") || strings.Contains(string(data), "s3cret") {
+		t.Fatalf("unexpected encrypted content %s", data)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0o640 {
+		t.Fatalf("mode = %v", info.Mode())
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"decrypt", "-key-file", keyFile}, bytes.NewReader(data), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != content {
+		t.Fatalf("decrypt = %q, want %q", out.String(), content)
+	}
+}
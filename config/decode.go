@@ -0,0 +1,100 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// unmarshal 使用 viper 将配置解析到 config 上，在 viper 默认的 decode hook 基础上支持 7d 格式的时间,
+// 以及 ByteSize 和 Days 类型的字符串
+func unmarshal(v *viper.Viper, config any) error {
+	return v.Unmarshal(config, viper.DecodeHook(decodeHook()))
+}
+
+// unmarshalStore 将 store 中的配置解析到 config 上并返回对应的 viper 实例，
+// caseStore 使用区分大小写的 mapstructure 解析，返回的 viper 实例中的 key 仍然不区分大小写，
+// 只有大小写不同的 key 在 viper 中只能保存一个，保留规则详见 foldKeys，结构体中的值不受影响
+func unmarshalStore(s store, config any, delim string) (*viper.Viper, error) {
+	if v, ok := s.(*viper.Viper); ok {
+		return v, unmarshal(v, config)
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       decodeHook(),
+		Result:           config,
+		WeaklyTypedInput: true,
+		MatchName:        exactMatch,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := decoder.Decode(s.AllSettings()); err != nil {
+		return nil, err
+	}
+
+	v := viper.NewWithOptions(viper.KeyDelimiter(delim))
+	if err := v.MergeConfigMap(foldKeys(s.AllSettings())); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		stringToDurationHook(),
+		stringToByteSizeHook(),
+		stringToDaysHook(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+// stringToDurationHook 将字符串转换为 time.Duration，除了 time.ParseDuration 支持的单位外还支持天 d
+func stringToDurationHook() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(time.Duration(0)) {
+			return data, nil
+		}
+
+		return parseDuration(data.(string))
+	}
+}
+
+var dayUnit = regexp.MustCompile(`(\d+(?:\.\d+)?)d`)
+
+// parseDuration 解析时间间隔，例如 7d, 1d12h, 90m
+func parseDuration(s string) (time.Duration, error) {
+	s = dayUnit.ReplaceAllStringFunc(s, func(d string) string {
+		n, _ := strconv.ParseFloat(d[:len(d)-1], 64)
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+
+	return time.ParseDuration(s)
+}
+
+// stringToByteSizeHook 将字符串转换为 ByteSize，例如 512MiB
+func stringToByteSizeHook() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(ByteSize(0)) {
+			return data, nil
+		}
+
+		return ParseByteSize(data.(string))
+	}
+}
+
+// stringToDaysHook 将字符串转换为 Days，例如 7d
+func stringToDaysHook() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data any) (any, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(Days(0)) {
+			return data, nil
+		}
+
+		return ParseDays(data.(string))
+	}
+}
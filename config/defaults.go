@@ -0,0 +1,142 @@
+package config
+
+import "reflect"
+
+// defaultTagName 默认值使用的 tag 名称, 例如 `default:"10000"`, `default:"7d"`, `default:"viper,ini"`
+const defaultTagName = "default"
+
+// applyDefaults 将 config 结构体上 default tag 声明的默认值设置到 v 中，配置文件中不存在的 key 会使用默认值，
+// 对于结构体类型的 slice 和 map，会对配置文件中的每一个元素补充缺失的 key
+func applyDefaults(v store, config any, delim string, match keyMatch) {
+	t := reflect.TypeOf(config)
+	if t == nil {
+		return
+	}
+
+	setDefaults(v, indirectType(t), "", delim, match)
+}
+
+func setDefaults(v store, t reflect.Type, prefix, delim string, match keyMatch) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		path := prefix
+		if !squash {
+			path = joinKey(prefix, key, delim)
+		}
+
+		if def, ok := f.Tag.Lookup(defaultTagName); ok {
+			v.SetDefault(path, def)
+			continue
+		}
+
+		ft := indirectType(f.Type)
+		switch ft.Kind() {
+		case reflect.Struct:
+			setDefaults(v, ft, path, delim, match)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if indirectType(ft.Elem()).Kind() != reflect.Struct || !v.InConfig(path) {
+				continue
+			}
+			if value := fillDefaults(v.Get(path), ft, match); value != nil {
+				v.Set(path, value)
+			}
+		}
+	}
+}
+
+// fillDefaults 为 slice 或者 map 中的每个结构体元素补充缺失 key 的默认值，value 不是 slice 或 map 时返回 nil
+func fillDefaults(value any, t reflect.Type, match keyMatch) any {
+	elem := indirectType(t.Elem())
+	switch items := value.(type) {
+	case []any:
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				fillStructDefaults(m, elem, match)
+			}
+		}
+		return items
+	case map[string]any:
+		if t.Kind() != reflect.Map {
+			return nil
+		}
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				fillStructDefaults(m, elem, match)
+			}
+		}
+		return items
+	}
+
+	return nil
+}
+
+func fillStructDefaults(m map[string]any, t reflect.Type, match keyMatch) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		ft := indirectType(f.Type)
+		if squash {
+			if ft.Kind() == reflect.Struct {
+				fillStructDefaults(m, ft, match)
+			}
+			continue
+		}
+
+		name, value, ok := lookupKey(m, key, match)
+		if def, hasDefault := f.Tag.Lookup(defaultTagName); hasDefault {
+			if !ok {
+				m[key] = def
+			}
+			continue
+		}
+
+		switch ft.Kind() {
+		case reflect.Struct:
+			sub, isMap := value.(map[string]any)
+			if !ok {
+				sub, isMap = make(map[string]any), true
+			}
+			if !isMap {
+				continue
+			}
+			fillStructDefaults(sub, ft, match)
+			if !ok && len(sub) > 0 {
+				m[key] = sub
+			}
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if ok && indirectType(ft.Elem()).Kind() == reflect.Struct {
+				if filled := fillDefaults(value, ft, match); filled != nil {
+					m[name] = filled
+				}
+			}
+		}
+	}
+}
+
+// lookupKey 在 map 中查找 key，优先精确匹配，其次使用 match 判断，默认与 mapstructure 一致不区分大小写
+func lookupKey(m map[string]any, key string, match keyMatch) (string, any, bool) {
+	if v, ok := m[key]; ok {
+		return key, v, true
+	}
+
+	for k, v := range m {
+		if match(k, key) {
+			return k, v, true
+		}
+	}
+
+	return "", nil, false
+}
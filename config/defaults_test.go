@@ -0,0 +1,68 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadConfigDefaults(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[server]\nname = test\nhttp_port = 8080\n\n[Note]\nContent = This is a test data\n")
+
+	var c struct {
+		Server struct {
+			Name     string        `mapstructure:"name" default:"unknown"`
+			HttpPort int           `mapstructure:"http_port" default:"10000"`
+			MaxAge   time.Duration `mapstructure:"max_age" default:"7d"`
+		} `mapstructure:"server"`
+		Note struct {
+			Content string   `mapstructure:"Content"`
+			UseLibs []string `mapstructure:"UseLibs" default:"viper,ini"`
+		}
+	}
+
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Server.Name != "test" || c.Server.HttpPort != 8080 || c.Server.MaxAge != 7*24*time.Hour {
+		t.Fatalf("unexpected server %+v", c.Server)
+	}
+	if !reflect.DeepEqual(c.Note.UseLibs, []string{"viper", "ini"}) {
+		t.Fatalf("UseLibs = %v", c.Note.UseLibs)
+	}
+}
+
+func TestReadConfigDefaultsInSlice(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.yaml")
+	writeFile(t, filename, "shards:\n  - host: a\n  - host: b\n    port: 3307\n    pool:\n      size: 20\n")
+
+	type shard struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port" default:"3306"`
+		Pool struct {
+			Size    int           `mapstructure:"size" default:"10"`
+			Timeout time.Duration `mapstructure:"timeout" default:"1m30s"`
+		} `mapstructure:"pool"`
+	}
+	var c struct {
+		Shards []shard `mapstructure:"shards"`
+	}
+
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Shards) != 2 {
+		t.Fatalf("shards = %+v", c.Shards)
+	}
+	a, b := c.Shards[0], c.Shards[1]
+	if a.Port != 3306 || a.Pool.Size != 10 || a.Pool.Timeout != 90*time.Second {
+		t.Errorf("shard a = %+v", a)
+	}
+	if b.Port != 3307 || b.Pool.Size != 20 || b.Pool.Timeout != 90*time.Second {
+		t.Errorf("shard b = %+v", b)
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretTagName 标记字段是否为敏感信息，`secret:"true"` 总是脱敏，`secret:"false"` 总是输出原值
+const secretTagName = "secret"
+
+// secretMask 敏感信息脱敏后输出的内容
+const secretMask = "******"
+
+// secretName 名称中包含 password, passwd, secret, token 或者以 key 结尾的字段默认为敏感信息
+var secretName = regexp.MustCompile(`(?i)(password|passwd|secret|token|key$)`)
+
+// Dump 将配置结构体序列化为 json, yaml, ini 或 cfg 格式，用于在启动时打印最终生效的配置，
+// key 与 ReadConfig 解析时使用的名称一致，敏感信息会输出为 ******，详见 secretTagName 和 secretName
+func Dump(target any, format string) ([]byte, error) {
+	tree := dumpValue(reflect.ValueOf(target))
+
+	switch strings.ToLower(format) {
+	case "json":
+		return json.MarshalIndent(tree, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(tree)
+	case "ini", "cfg":
+		root, ok := tree.(*orderedMap)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s requires a struct or map", ErrFileTypeNotAllow, format)
+		}
+		return dumpINI(root, keyDelimiter(strings.ToLower(format))), nil
+	}
+
+	return nil, ErrFileTypeNotAllow
+}
+
+// DumpDiff 对比两次配置的差异，用于配置重新加载时打印日志，每行一个变化的 key，
+// key 使用 format 对应的分割符，敏感信息发生变化时只输出 ******
+//
+//	~ key = old -> new   修改的 key
+//	+ key = value        新增的 key
+//	- key = value        删除的 key
+func DumpDiff(old, new any, format string) []byte {
+	delim := keyDelimiter(strings.ToLower(format))
+
+	before, after := make(map[string]any), make(map[string]any)
+	flattenTree(dumpValue(reflect.ValueOf(old)), "", delim, before)
+	flattenTree(dumpValue(reflect.ValueOf(new)), "", delim, after)
+
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			fmt.Fprintf(&buf, "+ %s = %s\n", k, formatLeaf(a))
+		case !inAfter:
+			fmt.Fprintf(&buf, "- %s = %s\n", k, formatLeaf(b))
+		case !reflect.DeepEqual(b, a):
+			fmt.Fprintf(&buf, "~ %s = %s -> %s\n", k, formatLeaf(b), formatLeaf(a))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// secretValue 敏感信息，序列化时输出 ******，对比差异时使用原值
+type secretValue struct {
+	value any
+}
+
+func (s secretValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(secretMask)
+}
+
+func (s secretValue) MarshalYAML() (any, error) {
+	return secretMask, nil
+}
+
+func (m *orderedMap) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range m.keys {
+		var value yaml.Node
+		if err := value.Encode(m.values[k]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, &value)
+	}
+
+	return node, nil
+}
+
+// dumpValue 将结构体转换为按字段顺序排列的树，结构体和 map 转换为 *orderedMap，slice 转换为 []any
+func dumpValue(v reflect.Value) any {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := newOrderedMap()
+		dumpStruct(v, m)
+		return m
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		m := newOrderedMap()
+		for _, k := range keys {
+			name := fmt.Sprint(k.Interface())
+			value := dumpValue(v.MapIndex(k))
+			if secretName.MatchString(name) {
+				value = secretValue{value: value}
+			}
+			m.set(name, value)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Array {
+				// 不可寻址的数组不能调用 Bytes，复制到 slice 中
+				b := make([]byte, v.Len())
+				reflect.Copy(reflect.ValueOf(b), v)
+				return string(b)
+			}
+			return string(v.Bytes())
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = dumpValue(v.Index(i))
+		}
+		return items
+	}
+
+	return v.Interface()
+}
+
+func dumpStruct(v reflect.Value, m *orderedMap) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		if squash {
+			if fv := indirectValue(v.Field(i)); fv.Kind() == reflect.Struct {
+				dumpStruct(fv, m)
+			}
+			continue
+		}
+
+		value := dumpValue(v.Field(i))
+		if isSecretField(f, key) {
+			value = secretValue{value: value}
+		}
+		m.set(key, value)
+	}
+}
+
+// IsSecretKey 判断 key 是否为敏感信息，规则与 Dump 一致，key 可以是使用分割符拼接的完整路径
+func IsSecretKey(key string) bool {
+	for _, sep := range []string{"::", "."} {
+		if i := strings.LastIndex(key, sep); i >= 0 {
+			key = key[i+len(sep):]
+		}
+	}
+
+	return secretName.MatchString(key)
+}
+
+func isSecretField(f reflect.StructField, key string) bool {
+	if tag, ok := f.Tag.Lookup(secretTagName); ok {
+		secret, _ := strconv.ParseBool(tag)
+		return secret
+	}
+
+	return secretName.MatchString(key) || secretName.MatchString(f.Name)
+}
+
+// flattenTree 将树展开为一层，只保留叶子节点
+func flattenTree(tree any, prefix, delim string, out map[string]any) {
+	m, ok := tree.(*orderedMap)
+	if !ok {
+		if prefix != "" {
+			out[prefix] = tree
+		}
+		return
+	}
+
+	for _, k := range m.keys {
+		flattenTree(m.values[k], joinKey(prefix, k, delim), delim, out)
+	}
+}
+
+// formatLeaf 将叶子节点格式化为一行字符串
+func formatLeaf(value any) string {
+	switch v := value.(type) {
+	case secretValue:
+		return secretMask
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			if _, ok := item.(*orderedMap); ok {
+				data, _ := json.Marshal(v)
+				return string(data)
+			}
+			items[i] = formatLeaf(item)
+		}
+		return strings.Join(items, ",")
+	case *orderedMap:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// dumpINI 输出 ini 格式，第一层的值输出在所有 section 之前，嵌套的结构体使用分割符拼接为 section 名称
+func dumpINI(root *orderedMap, delim string) []byte {
+	var buf bytes.Buffer
+	writeSection(&buf, root, "", delim)
+
+	return buf.Bytes()
+}
+
+func writeSection(buf *bytes.Buffer, m *orderedMap, name, delim string) {
+	var sections []string
+	wroteHeader := name == ""
+	for _, k := range m.keys {
+		if _, ok := m.values[k].(*orderedMap); ok {
+			sections = append(sections, k)
+			continue
+		}
+
+		if !wroteHeader {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			fmt.Fprintf(buf, "[%s]\n", name)
+			wroteHeader = true
+		}
+		fmt.Fprintf(buf, "%s = %s\n", k, iniValue(formatLeaf(m.values[k])))
+	}
+
+	for _, k := range sections {
+		writeSection(buf, m.values[k].(*orderedMap), joinKey(name, k, delim), delim)
+	}
+}
+
+// iniValue 值中包含注释符号或者换行时使用反引号包裹，详见 README 中的已知问题
+func iniValue(s string) string {
+	if strings.ContainsAny(s, "#;\n") {
+		return "`" + s + "`"
+	}
+
+	return s
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dumpConfig struct {
+	Server struct {
+		Name      string        `mapstructure:"name"`
+		HttpPort  int           `mapstructure:"http_port"`
+		TraceAddr string        `mapstructure:"trace.addr"`
+		Timeout   time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"server"`
+	DB struct {
+		User     string `mapstructure:"USER"`
+		Password string `mapstructure:"password"`
+		Dsn      string `mapstructure:"dsn" secret:"true"`
+		ApiKey   string `mapstructure:"api_key" secret:"false"`
+	} `mapstructure:"DB"`
+	Note struct {
+		UseLibs []string `mapstructure:"UseLibs"`
+	}
+}
+
+func newDumpConfig() *dumpConfig {
+	c := new(dumpConfig)
+	c.Server.Name = "test"
+	c.Server.HttpPort = 10000
+	c.Server.TraceAddr = "http://0.0.0.0:7820/#/traces"
+	c.Server.Timeout = 90 * time.Second
+	c.DB.User = "domob"
+	c.DB.Password = "s3cret"
+	c.DB.Dsn = "root:s3cret@tcp(127.0.0.1)"
+	c.DB.ApiKey = "public"
+	c.Note.UseLibs = []string{"viper", "ini"}
+	return c
+}
+
+func TestDump(t *testing.T) {
+	c := newDumpConfig()
+
+	data, err := Dump(c, "cfg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[server]
+name = test
+http_port = 10000
+trace.addr = ` + "`http://0.0.0.0:7820/#/traces`" + `
+timeout = 1m30s
+
+[DB]
+USER = domob
+password = ******
+dsn = ******
+api_key = public
+
+[Note]
+UseLibs = viper,ini
+`
+	if string(data) != want {
+		t.Fatalf("cfg dump:\n%s\nwant:\n%s", data, want)
+	}
+
+	// dump 的结果可以重新被解析
+	filename := filepath.Join(t.TempDir(), "dump.cfg")
+	writeFile(t, filename, string(data))
+	var parsed dumpConfig
+	if _, err := ReadConfig(&parsed, filename); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Server != c.Server || strings.Join(parsed.Note.UseLibs, ",") != "viper,ini" {
+		t.Fatalf("round trip mismatch %+v", parsed)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		data, err := Dump(c, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "s3cret") || !strings.Contains(string(data), secretMask) || !strings.Contains(string(data), "public") {
+			t.Fatalf("%s dump leaks or misses values:\n%s", format, data)
+		}
+		if strings.Index(string(data), "http_port") < strings.Index(string(data), "name") {
+			t.Fatalf("%s dump does not keep field order:\n%s", format, data)
+		}
+	}
+
+	if _, err := Dump(c, "xml"); !errors.Is(err, ErrFileTypeNotAllow) {
+		t.Fatalf("err = %v, want ErrFileTypeNotAllow", err)
+	}
+}
+
+func TestDumpDiff(t *testing.T) {
+	old, new := newDumpConfig(), newDumpConfig()
+	new.Server.HttpPort = 10001
+	new.DB.Password = "changed"
+	new.Note.UseLibs = append(new.Note.UseLibs, "yaml")
+
+	want := `~ DB::password = ****** -> ******
+~ Note::UseLibs = viper,ini -> viper,ini,yaml
+~ server::http_port = 10000 -> 10001
+`
+	if got := string(DumpDiff(old, new, "cfg")); got != want {
+		t.Fatalf("diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := DumpDiff(old, old, "yaml"); len(got) != 0 {
+		t.Fatalf("diff of same value = %q", got)
+	}
+}
+
+func TestDumpByteArray(t *testing.T) {
+	type A struct {
+		ID  [4]byte `mapstructure:"id"`
+		Raw []byte  `mapstructure:"raw"`
+	}
+
+	// 非指针的结构体中的数组不可寻址
+	data, err := Dump(A{ID: [4]byte{'a', 'b', 'c', 'd'}, Raw: []byte("xy")}, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"id": "abcd"`) || !strings.Contains(string(data), `"raw": "xy"`) {
+		t.Fatalf("unexpected dump %s", data)
+	}
+}
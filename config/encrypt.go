@@ -0,0 +1,286 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrDecrypt 解密 ENC[AES256-GCM,...] 形式的配置值失败
+var ErrDecrypt = errors.New("decrypt config value failed")
+
+const (
+	// KeyEnv 没有设置 WithKey/WithKeyFile 时读取该环境变量作为 base64 编码的密钥
+	KeyEnv = "CONFIG_KEY"
+	// KeyFileEnv 没有设置 WithKey/WithKeyFile 以及 CONFIG_KEY 时读取该环境变量指定的密钥文件
+	KeyFileEnv = "CONFIG_KEY_FILE"
+
+	encAlgorithm = "AES256-GCM"
+	keySize      = 32
+)
+
+var (
+	// encToken 加密后的值，例如 ENC[AES256-GCM,base64(nonce+ciphertext)]
+	encToken = regexp.MustCompile(`ENC\[` + encAlgorithm + `,([A-Za-z0-9+/=]+)\]`)
+	// decToken 待加密的明文，cfgcrypt 会将其替换为 ENC[...]，明文中不能包含 ]
+	decToken = regexp.MustCompile(`DEC\[([^\]]*)\]`)
+)
+
+// GenerateKey 生成 base64 编码的随机密钥，可以直接写入密钥文件或者 CONFIG_KEY 环境变量
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey 解析 base64 编码的密钥，解码后必须为 32 字节
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key: must be %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+// ReadKeyFile 读取密钥文件，文件内容为 GenerateKey 生成的 base64 字符串
+func ReadKeyFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKey(string(data))
+}
+
+// LoadKey 按优先级加载密钥: keyFile 不为空时读取该文件，否则依次使用环境变量 CONFIG_KEY, CONFIG_KEY_FILE
+func LoadKey(keyFile string) ([]byte, error) {
+	if keyFile != "" {
+		return ReadKeyFile(keyFile)
+	}
+	if s, ok := os.LookupEnv(KeyEnv); ok && s != "" {
+		return ParseKey(s)
+	}
+	if filename, ok := os.LookupEnv(KeyFileEnv); ok && filename != "" {
+		return ReadKeyFile(filename)
+	}
+
+	return nil, fmt.Errorf("no key, set %s, %s or use a key file", KeyEnv, KeyFileEnv)
+}
+
+// Encrypt 使用 AES-256-GCM 加密 plaintext，返回 ENC[AES256-GCM,...] 形式的值
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "ENC[" + encAlgorithm + "," + base64.StdEncoding.EncodeToString(sealed) + "]", nil
+}
+
+// Decrypt 解密 Encrypt 返回的 ENC[AES256-GCM,...] 形式的值
+func Decrypt(key []byte, value string) (string, error) {
+	m := encToken.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || m[0] != strings.TrimSpace(value) {
+		return "", fmt.Errorf("invalid encrypted value %q", value)
+	}
+
+	return decryptToken(key, m[1])
+}
+
+func decryptToken(key []byte, token string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key: must be %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// EncryptValues 将 data 中所有 DEC[明文] 替换为 ENC[AES256-GCM,...]，只替换文本不解析格式，因此适用于所有支持的格式
+func EncryptValues(key []byte, data []byte) ([]byte, error) {
+	var err error
+	out := decToken.ReplaceAllFunc(data, func(m []byte) []byte {
+		if err != nil {
+			return m
+		}
+		var enc string
+		enc, err = Encrypt(key, string(decToken.FindSubmatch(m)[1]))
+		return []byte(enc)
+	})
+
+	return out, err
+}
+
+// DecryptValues 将 data 中所有 ENC[AES256-GCM,...] 替换为 DEC[明文]，与 EncryptValues 互逆
+func DecryptValues(key []byte, data []byte) ([]byte, error) {
+	out, err := replaceEnc(key, data, func(plain string) (string, error) {
+		if strings.Contains(plain, "]") {
+			return "", errors.New("plaintext contains ]")
+		}
+		return "DEC[" + plain + "]", nil
+	})
+	if err != nil {
+		return nil, errors.Join(ErrDecrypt, err)
+	}
+
+	return out, nil
+}
+
+// replaceEnc 解密 data 中所有 ENC[AES256-GCM,...] 并使用 wrap 处理后的明文替换
+func replaceEnc(key []byte, data []byte, wrap func(string) (string, error)) ([]byte, error) {
+	var err error
+	out := encToken.ReplaceAllFunc(data, func(m []byte) []byte {
+		if err != nil {
+			return m
+		}
+		var plain string
+		if plain, err = decryptToken(key, string(encToken.FindSubmatch(m)[1])); err != nil {
+			return m
+		}
+		if plain, err = wrap(plain); err != nil {
+			return m
+		}
+		return []byte(plain)
+	})
+
+	return out, err
+}
+
+// key 获取解密使用的密钥，优先级 WithKey/WithKeyFile > CONFIG_KEY > CONFIG_KEY_FILE
+func (o *options) key() ([]byte, error) {
+	if o.encKey != nil {
+		return o.encKey, nil
+	}
+
+	return LoadKey(o.keyFile)
+}
+
+// decrypt 解密 v 中所有 ENC[AES256-GCM,...] 形式的字符串值，只有存在加密的值时才会读取密钥
+func (o *options) decrypt(v store) error {
+	var (
+		key  []byte
+		errs []error
+	)
+	for _, k := range v.AllKeys() {
+		value := v.Get(k)
+		if !containsEnc(value) {
+			continue
+		}
+
+		if key == nil {
+			var err error
+			if key, err = o.key(); err != nil {
+				return errors.Join(ErrDecrypt, fmt.Errorf("key %q: %w", k, err))
+			}
+		}
+
+		value, err := decryptValue(key, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", k, err))
+			continue
+		}
+		v.Set(k, value)
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(ErrDecrypt, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func containsEnc(value any) bool {
+	switch val := value.(type) {
+	case string:
+		return strings.Contains(val, "ENC[")
+	case []any:
+		for _, item := range val {
+			if containsEnc(item) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, item := range val {
+			if containsEnc(item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decryptValue(key []byte, value any) (any, error) {
+	switch val := value.(type) {
+	case string:
+		out, err := replaceEnc(key, []byte(val), func(plain string) (string, error) {
+			return plain, nil
+		})
+		return string(out), err
+	case []any:
+		for i, item := range val {
+			s, err := decryptValue(key, item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = s
+		}
+		return val, nil
+	case map[string]any:
+		for k, item := range val {
+			s, err := decryptValue(key, item)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = s
+		}
+		return val, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+
+	s, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestEncryptValues(t *testing.T) {
+	key := testKey(t)
+
+	data := []byte("[db]\nuser = root\npassword = DEC[p@ss=word;1]\ntoken = DEC[]\n")
+	enc, err := EncryptValues(key, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(enc), "p@ss") || strings.Count(string(enc), "ENC[AES256-GCM,") != 2 {
+		t.Fatalf("unexpected encrypted content %s", enc)
+	}
+
+	dec, err := DecryptValues(key, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dec) != string(data) {
+		t.Fatalf("DecryptValues = %s, want %s", dec, data)
+	}
+
+	if _, err := DecryptValues(testKey(t), enc); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("err = %v, want ErrDecrypt", err)
+	}
+}
+
+type secretConfig struct {
+	DB struct {
+		User     string   `mapstructure:"user"`
+		Password string   `mapstructure:"password"`
+		Tokens   []string `mapstructure:"tokens"`
+	} `mapstructure:"db"`
+}
+
+func TestReadConfigDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	key := testKey(t)
+	password, err := Encrypt(key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := Encrypt(key, "t1")
+
+	keyFile := filepath.Join(dir, "config.key")
+	s, _ := GenerateKey()
+	writeFile(t, keyFile, s+"\n")
+
+	files := map[string]string{
+		"secret.cfg":  "[db]\nuser = root\npassword = " + password + "\ntokens = " + token + ",plain\n",
+		"secret.yaml": "db:\n  user: root\n  password: " + password + "\n  tokens:\n    - " + token + "\n    - plain\n",
+		"secret.toml": "[db]\nuser = \"root\"\npassword = \"" + password + "\"\ntokens = [\"" + token + "\", \"plain\"]\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		writeFile(t, filename, content)
+
+		var c secretConfig
+		if _, err := ReadConfig(&c, filename, WithKey(key)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c.DB.Password != "s3cret" || len(c.DB.Tokens) != 2 || c.DB.Tokens[0] != "t1" {
+			t.Fatalf("%s: unexpected config %+v", name, c)
+		}
+
+		_, err := ReadConfig(&c, filename, WithKeyFile(keyFile))
+		if !errors.Is(err, ErrDecrypt) || !strings.Contains(err.Error(), "password") {
+			t.Fatalf("%s: err = %v, want ErrDecrypt", name, err)
+		}
+	}
+
+	t.Setenv(KeyEnv, "")
+	t.Setenv(KeyFileEnv, "")
+	var c secretConfig
+	if _, err := ReadConfig(&c, filepath.Join(dir, "secret.cfg")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("err = %v, want ErrDecrypt", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	keys := make([]string, 3)
+	for i := range keys {
+		s, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = s
+	}
+	keyFile := filepath.Join(dir, "app.key")
+	envKeyFile := filepath.Join(dir, "env.key")
+	writeFile(t, keyFile, keys[0])
+	writeFile(t, envKeyFile, keys[2])
+
+	t.Setenv(KeyEnv, keys[1])
+	t.Setenv(KeyFileEnv, envKeyFile)
+
+	cases := []struct {
+		keyFile string
+		env     string
+		want    string
+	}{
+		{keyFile: keyFile, want: keys[0]},
+		{want: keys[1]},
+		{env: KeyEnv, want: keys[2]},
+	}
+	for _, c := range cases {
+		if c.env != "" {
+			t.Setenv(c.env, "")
+		}
+		got, err := LoadKey(c.keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, _ := ParseKey(c.want); string(got) != string(want) {
+			t.Fatalf("LoadKey(%q) returned unexpected key", c.keyFile)
+		}
+	}
+
+	t.Setenv(KeyFileEnv, "")
+	if _, err := LoadKey(""); err == nil {
+		t.Fatal("expected error without key")
+	}
+}
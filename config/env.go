@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pemako/gopkg/envload"
+)
+
+// envLevelSeparator 环境变量名称中用于分割层级的字符串
+const envLevelSeparator = "__"
+
+// applyEnv 使用环境变量覆盖 v 中对应 key 的值
+func (o *options) applyEnv(v store, delim string) {
+	if !o.envEnabled {
+		return
+	}
+
+	loader := o.envLoader
+	if loader == nil {
+		loader = envload.New()
+	}
+
+	for _, kv := range loader.Environ(context.Background()) {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 {
+			continue
+		}
+
+		if key := envKey(kv[:i], o.envPrefix, delim); key != "" {
+			if cs, ok := v.(*caseStore); ok {
+				key = cs.resolve(key)
+			}
+			v.Set(key, kv[i+1:])
+		}
+	}
+}
+
+// envKey 将环境变量名称转换为配置的 key, 不匹配 prefix 时返回空字符串
+// 例如 APP_SERVER__HTTP_PORT => server::http_port
+func envKey(name, prefix, delim string) string {
+	if prefix != "" {
+		if !strings.HasSuffix(prefix, "_") {
+			prefix += "_"
+		}
+		if !strings.HasPrefix(name, prefix) {
+			return ""
+		}
+		name = name[len(prefix):]
+	}
+
+	parts := strings.Split(name, envLevelSeparator)
+	for i, p := range parts {
+		if p == "" {
+			return ""
+		}
+		parts[i] = strings.ToLower(p)
+	}
+
+	return strings.Join(parts, delim)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pemako/gopkg/envload"
+)
+
+func TestReadConfigEnvOverlay(t *testing.T) {
+	dir := t.TempDir()
+	envdir := filepath.Join(dir, "envdir")
+	filename := filepath.Join(dir, "server.cfg")
+	writeFile(t, filename, "[server]\nname = file\nhttp_port = 10000\n\n[DB]\nUSER = domob\n")
+	mkdir(t, envdir)
+	writeFile(t, filepath.Join(envdir, "APP_DB__PASSWORD"), "secret\n")
+
+	var c struct {
+		Server struct {
+			Name     string `mapstructure:"name"`
+			HttpPort int    `mapstructure:"http_port"`
+		} `mapstructure:"server"`
+		DB struct {
+			User     string `mapstructure:"USER"`
+			Password string `mapstructure:"password"`
+		}
+	}
+
+	loader := envload.New(
+		"APP_SERVER__HTTP_PORT=8080",
+		"APP_DB__USER=root",
+		"OTHER_SERVER__NAME=ignored",
+		"ENVDIR="+envdir,
+	)
+	v, err := ReadConfig(&c, filename, WithEnvPrefix("APP"), WithEnvLoader(loader))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Server.Name != "file" || c.Server.HttpPort != 8080 || c.DB.User != "root" || c.DB.Password != "secret" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if got := v.GetInt("server::http_port"); got != 8080 {
+		t.Fatalf("server::http_port = %d", got)
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	tests := []struct {
+		name, prefix, delim, want string
+	}{
+		{"APP_SERVER__HTTP_PORT", "APP", "::", "server::http_port"},
+		{"APP_SERVER__HTTP_PORT", "APP_", ".", "server.http_port"},
+		{"APP_SERVER____PORT", "APP", ".", ""},
+		{"SERVER__NAME", "APP", ".", ""},
+		{"SERVER__NAME", "", ".", "server.name"},
+	}
+
+	for _, tt := range tests {
+		if got := envKey(tt.name, tt.prefix, tt.delim); got != tt.want {
+			t.Errorf("envKey(%q, %q) = %q, want %q", tt.name, tt.prefix, got, tt.want)
+		}
+	}
+}
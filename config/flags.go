@@ -0,0 +1,148 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// flagTagName 自定义命令行参数名称使用的 tag，例如 `flag:"port,p"` 中 p 为 pflag 的短参数，`flag:"-"` 不注册参数
+const flagTagName = "flag"
+
+// flagField 结构体中一个可以通过命令行参数设置的字段
+type flagField struct {
+	name      string // 参数名称，例如 server.http-port
+	shorthand string
+	path      []string // 配置中的 key，使用分割符拼接后即为 viper 中的 key
+	usage     string
+	value     string // default tag 声明的默认值，只用于帮助信息
+	isBool    bool
+}
+
+// BindFlags 根据 target 结构体在 fs 中注册命令行参数，参数名称为小写的 key 使用 . 拼接并将 _ 替换为 -，
+// 例如 server.http_port 对应 --server.http-port，帮助信息使用 desc tag，默认值使用 default tag。
+// 解析参数后通过 WithFlags 传给 ReadConfig，只有命令行中指定的参数会覆盖配置，优先级为 默认值 < 文件 < 环境变量 < 命令行参数
+func BindFlags(fs *pflag.FlagSet, target any) {
+	for _, f := range flagFields(target) {
+		if f.isBool {
+			fs.BoolP(f.name, f.shorthand, f.value == "true", f.usage)
+			continue
+		}
+		fs.StringP(f.name, f.shorthand, f.value, f.usage)
+	}
+}
+
+// BindGoFlags 与 BindFlags 一致，使用标准库的 flag，不支持短参数
+func BindGoFlags(fs *flag.FlagSet, target any) {
+	for _, f := range flagFields(target) {
+		if f.isBool {
+			fs.Bool(f.name, f.value == "true", f.usage)
+			continue
+		}
+		fs.String(f.name, f.value, f.usage)
+	}
+}
+
+// pflagValues 获取命令行中指定的参数
+func pflagValues(fs *pflag.FlagSet) func() map[string]string {
+	return func() map[string]string {
+		values := make(map[string]string)
+		fs.Visit(func(f *pflag.Flag) {
+			values[f.Name] = f.Value.String()
+		})
+		return values
+	}
+}
+
+// goFlagValues 获取命令行中指定的参数
+func goFlagValues(fs *flag.FlagSet) func() map[string]string {
+	return func() map[string]string {
+		values := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			values[f.Name] = f.Value.String()
+		})
+		return values
+	}
+}
+
+// applyFlags 使用命令行中指定的参数覆盖 v 中对应 key 的值
+func (o *options) applyFlags(v store, config any, delim string) {
+	if o.flagValues == nil {
+		return
+	}
+
+	values := o.flagValues()
+	if len(values) == 0 {
+		return
+	}
+
+	for _, f := range flagFields(config) {
+		if value, ok := values[f.name]; ok {
+			v.Set(strings.Join(f.path, delim), value)
+		}
+	}
+}
+
+func flagFields(target any) []flagField {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return nil
+	}
+
+	var fields []flagField
+	collectFlags(indirectType(t), nil, &fields)
+
+	return fields
+}
+
+func collectFlags(t reflect.Type, prefix []string, out *[]flagField) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, skip := fieldKey(f)
+		if skip || f.Tag.Get(flagTagName) == "-" {
+			continue
+		}
+
+		ft := indirectType(f.Type)
+		if squash {
+			collectFlags(ft, prefix, out)
+			continue
+		}
+
+		path := append(prefix[:len(prefix):len(prefix)], key)
+		switch {
+		case ft == durationType || ft == timeType:
+		case ft.Kind() == reflect.Struct:
+			collectFlags(ft, path, out)
+			continue
+		case ft.Kind() == reflect.Map, ft.Kind() == reflect.Interface:
+			continue
+		case ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array:
+			if k := indirectType(ft.Elem()).Kind(); k == reflect.Struct || k == reflect.Map || k == reflect.Slice {
+				continue
+			}
+		}
+
+		field := flagField{
+			name:   strings.ReplaceAll(strings.ToLower(strings.Join(path, ".")), "_", "-"),
+			path:   path,
+			usage:  f.Tag.Get(descTagName),
+			value:  f.Tag.Get(defaultTagName),
+			isBool: ft.Kind() == reflect.Bool,
+		}
+		if tag := f.Tag.Get(flagTagName); tag != "" {
+			name, shorthand, _ := strings.Cut(tag, ",")
+			if name != "" {
+				field.name = name
+			}
+			field.shorthand = shorthand
+		}
+		*out = append(*out, field)
+	}
+}
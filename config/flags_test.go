@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type flagConfig struct {
+	Server struct {
+		Name     string        `mapstructure:"name" desc:"service name"`
+		HttpPort int           `mapstructure:"http_port" default:"8080" desc:"listen port" flag:"port,p"`
+		Debug    bool          `mapstructure:"debug"`
+		Timeout  time.Duration `mapstructure:"timeout" default:"5s"`
+		Secret   string        `mapstructure:"secret" flag:"-"`
+	} `mapstructure:"server"`
+	Note struct {
+		UseLibs []string `mapstructure:"UseLibs"`
+	} `mapstructure:"note"`
+}
+
+func TestBindFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[server]\nname = file\nhttp_port = 10000\ntimeout = 1s\n")
+	t.Setenv("APP_SERVER__NAME", "env")
+	t.Setenv("APP_SERVER__HTTP_PORT", "10001")
+
+	var c flagConfig
+	fs := pflag.NewFlagSet("app", pflag.ContinueOnError)
+	BindFlags(fs, &c)
+	if fs.Lookup("server.secret") != nil {
+		t.Fatal("flag:\"-\" should not be registered")
+	}
+
+	var help bytes.Buffer
+	fs.SetOutput(&help)
+	fs.PrintDefaults()
+	for _, want := range []string{"-p, --port string", "listen port (default \"8080\")", "--server.name string", "--note.uselibs string", "--server.debug"} {
+		if !strings.Contains(help.String(), want) {
+			t.Fatalf("help %q does not contain %q", help.String(), want)
+		}
+	}
+
+	if err := fs.Parse([]string{"-p", "10002", "--server.debug", "--note.uselibs", "viper,ini"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(&c, filename, WithEnvPrefix("APP"), WithFlags(fs)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := c.Server
+	if s.Name != "env" || s.HttpPort != 10002 || !s.Debug || s.Timeout != time.Second || len(c.Note.UseLibs) != 2 {
+		t.Fatalf("unexpected config %+v %+v", s, c.Note)
+	}
+}
+
+func TestBindGoFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.yaml")
+	writeFile(t, filename, "server:\n  name: file\n")
+
+	var c flagConfig
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	BindGoFlags(fs, &c)
+	if err := fs.Parse([]string{"-server.timeout", "7d", "-server.debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadConfig(&c, filename, WithGoFlags(fs)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := c.Server
+	if s.Name != "file" || s.HttpPort != 8080 || !s.Debug || s.Timeout != 7*24*time.Hour {
+		t.Fatalf("unexpected config %+v", s)
+	}
+}
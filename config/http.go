@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+// 默认的 HTTP 轮询间隔
+const defaultPollInterval = 30 * time.Second
+
+// contentTypes Content-Type 与配置类型的对应关系
+var contentTypes = map[string]string{
+	"application/json":       "json",
+	"application/yaml":       "yaml",
+	"application/x-yaml":     "yaml",
+	"text/yaml":              "yaml",
+	"text/x-yaml":            "yaml",
+	"application/toml":       "toml",
+	"text/x-java-properties": "properties",
+}
+
+// HTTPProvider 通过 HTTP(S) GET 获取配置，使用 ETag 判断内容是否变化，
+// 类型依次根据 Content-Type 和 URL 路径的后缀判断，都无法判断时根据内容判断
+type HTTPProvider struct {
+	url      string
+	client   *http.Client
+	header   http.Header
+	interval time.Duration
+
+	mu     sync.Mutex
+	etag   string
+	data   []byte
+	format string
+}
+
+// NewHTTPProvider 创建请求 rawURL 的 Provider，可以通过 WithHTTPClient, WithHeader, WithPollInterval 设置
+func NewHTTPProvider(rawURL string, opts ...Option) (*HTTPProvider, error) {
+	if _, err := url.Parse(rawURL); err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	p := &HTTPProvider{
+		url:      rawURL,
+		client:   o.httpClient,
+		header:   o.header,
+		interval: o.pollInterval,
+	}
+	if p.client == nil {
+		p.client = http.DefaultClient
+	}
+	if p.interval <= 0 {
+		p.interval = defaultPollInterval
+	}
+
+	return p, nil
+}
+
+func (p *HTTPProvider) Read() ([]byte, string, error) {
+	if _, err := p.fetch(context.Background()); err != nil {
+		return nil, "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.data, p.format, nil
+}
+
+// Watch 每隔 WithPollInterval 设置的时间请求一次，服务端返回 304 或者内容没有变化时不通知
+func (p *HTTPProvider) Watch(ctx context.Context, notify func(error)) error {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := p.fetch(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						notify(err)
+					}
+					continue
+				}
+				if changed {
+					notify(nil)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// fetch 请求配置内容，返回内容是否发生变化
+func (p *HTTPProvider) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	for k, values := range p.header {
+		req.Header[k] = values
+	}
+
+	p.mu.Lock()
+	if p.etag != "" && p.data != nil {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	p.mu.Unlock()
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("GET %s: %s", p.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changed := p.data == nil || !bytes.Equal(p.data, data)
+	p.data, p.etag = data, resp.Header.Get("ETag")
+	p.format = p.contentType(resp.Header.Get("Content-Type"))
+
+	return changed, nil
+}
+
+func (p *HTTPProvider) contentType(header string) string {
+	if mediaType, _, err := mime.ParseMediaType(header); err == nil {
+		if format, ok := contentTypes[mediaType]; ok {
+			return format
+		}
+	}
+
+	if u, err := url.Parse(p.url); err == nil {
+		return extType(path.Base(u.Path))
+	}
+
+	return ""
+}
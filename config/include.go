@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// ErrInclude 展开 ini/cfg 文件中的 include 指令失败
+var ErrInclude = errors.New("include config failed")
+
+// includer 读取 include 指令引用的文件，fsys 为空时读取本地文件，fsys 和 name 都为空时不允许 include，
+// hash 不为空时写入所有读取的内容，用于计算 Snapshot.Hash
+type includer struct {
+	fsys fs.FS
+	name string
+	hash hash.Hash
+}
+
+func (inc *includer) read(name string) (data []byte, err error) {
+	if inc.fsys == nil {
+		data, err = os.ReadFile(name)
+	} else {
+		data, err = fs.ReadFile(inc.fsys, name)
+	}
+	if err == nil {
+		inc.digest(data)
+	}
+
+	return data, err
+}
+
+// digest 将 data 写入 hash，先写入长度，避免不同文件的内容拼接后相同
+func (inc *includer) digest(data []byte) {
+	if inc.hash == nil {
+		return
+	}
+
+	fmt.Fprintf(inc.hash, "%d:", len(data))
+	inc.hash.Write(data)
+}
+
+// resolve 获取 ref 相对于 base 文件所在目录的路径
+func (inc *includer) resolve(base, ref string) string {
+	if inc.fsys == nil {
+		if filepath.IsAbs(ref) {
+			return filepath.Clean(ref)
+		}
+		return filepath.Join(filepath.Dir(base), ref)
+	}
+
+	return path.Join(path.Dir(base), ref)
+}
+
+// id 获取用于检测循环引用的文件标识
+func (inc *includer) id(name string) string {
+	if inc.fsys == nil {
+		if abs, err := filepath.Abs(name); err == nil {
+			return abs
+		}
+	}
+
+	return path.Clean(name)
+}
+
+// includeDirective 判断一行是否为 include 指令，支持 `include = common.cfg` 和 `!include common.cfg` 两种形式，
+// `include = ` 只在第一个 section 之前以及 [DEFAULT] 中生效，section 中名为 include 的 key 仍然是普通的 key，
+// 路径后面 ; 或者 # 开头的行尾注释会被去掉
+func includeDirective(line, section string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if rest, ok := strings.CutPrefix(line, "!include"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+		return stripInlineComment(rest), true
+	}
+
+	if section != "" && !strings.EqualFold(section, ini.DefaultSection) {
+		return "", false
+	}
+	if key, value, ok := iniKeyValue(line); ok && strings.EqualFold(key, "include") {
+		return stripInlineComment(value), true
+	}
+
+	return "", false
+}
+
+func stripInlineComment(s string) string {
+	if i := inlineComment(s); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// expandIncludes 展开 ini/cfg 内容中的 include 指令，被引用文件的内容替换指令所在的行，
+// 相对路径基于当前文件所在的目录，被引用文件中没有 section 的 key 属于指令所在的 section，
+// 重复的 section 和 key 后出现的覆盖先出现的
+func (inc *includer) expandIncludes(data []byte) ([]byte, error) {
+	return inc.expand(data, inc.name, []string{inc.id(inc.name)})
+}
+
+func (inc *includer) expand(data []byte, name string, stack []string) ([]byte, error) {
+	var buf bytes.Buffer
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if s, ok := iniSection(line); ok {
+			section = s
+		}
+
+		ref, ok := includeDirective(line, section)
+		if !ok {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+			continue
+		}
+		if ref == "" {
+			return nil, errors.Join(ErrInclude, fmt.Errorf("%s: empty include directive", name))
+		}
+		if inc.fsys == nil && inc.name == "" {
+			// HTTPProvider, MemoryProvider 以及 ReadConfigBytes 等内容没有对应的文件，不能从当前目录读取本地文件
+			return nil, errors.Join(ErrInclude, fmt.Errorf("include %q is not allowed in content without a file", ref))
+		}
+
+		target := inc.resolve(name, ref)
+		id := inc.id(target)
+		for _, s := range stack {
+			if s == id {
+				return nil, errors.Join(ErrInclude, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), id))
+			}
+		}
+
+		included, err := inc.read(target)
+		if err != nil {
+			return nil, errors.Join(ErrInclude, fmt.Errorf("%s: %w", name, err))
+		}
+
+		included, err = inc.expand(included, target, append(stack[:len(stack):len(stack)], id))
+		if err != nil {
+			return nil, err
+		}
+
+		// 恢复指令所在的 section，避免后面的 key 被归入被引用文件中的最后一个 section
+		buf.Write(included)
+		if section != "" {
+			fmt.Fprintf(&buf, "[%s]\n", section)
+		} else {
+			buf.WriteString("[DEFAULT]\n")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(ErrInclude, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type includeConfig struct {
+	AppMode string `mapstructure:"app_mode"`
+	Server  struct {
+		Name     string `mapstructure:"name"`
+		HttpPort int    `mapstructure:"http_port"`
+		Debug    bool   `mapstructure:"debug"`
+	} `mapstructure:"server"`
+	DB struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	} `mapstructure:"db"`
+}
+
+func TestReadConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	mkdir(t, filepath.Join(dir, "common"))
+	writeFile(t, filepath.Join(dir, "common", "base.cfg"), "[server]\nname = base\nhttp_port = 8080\ndebug = true\n\n!include db.cfg # 数据库配置\n")
+	writeFile(t, filepath.Join(dir, "common", "db.cfg"), "[db]\nhost = localhost\nport = 3306\n")
+	writeFile(t, filepath.Join(dir, "port.cfg"), "port = 3307\n")
+	filename := filepath.Join(dir, "server.cfg")
+	writeFile(t, filename, "include = common/base.cfg ; shared settings\napp_mode = dev\n\n[server]\nhttp_port = 10000 ; 覆盖 base 中的值\n\n[db]\n!include port.cfg\n")
+
+	var c includeConfig
+	v, err := ReadConfig(&c, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Server.Name != "base" || c.Server.HttpPort != 10000 || !c.Server.Debug || c.DB.Host != "localhost" || c.DB.Port != 3307 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if got := v.GetString("default::app_mode"); got != "dev" {
+		t.Fatalf("default::app_mode = %q", got)
+	}
+}
+
+func TestReadConfigIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.cfg"), "!include b.cfg\n")
+	writeFile(t, filepath.Join(dir, "b.cfg"), "[server]\n!include a.cfg\n")
+
+	var c includeConfig
+	_, err := ReadConfig(&c, filepath.Join(dir, "a.cfg"))
+	if !errors.Is(err, ErrInclude) || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("err = %v, want include cycle", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "c.cfg"), "!include missing.cfg\n")
+	if _, err := ReadConfig(&c, filepath.Join(dir, "c.cfg")); !errors.Is(err, ErrInclude) {
+		t.Fatalf("err = %v, want ErrInclude", err)
+	}
+}
+
+func TestReadConfigFSInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/server.cfg": {Data: []byte("[server]\nname = embed\n!include ../shared/port.cfg\n")},
+		"shared/port.cfg": {Data: []byte("http_port = 9000\n")},
+	}
+
+	var c includeConfig
+	if _, err := ReadConfigFS(&c, fsys, "conf/server.cfg"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "embed" || c.Server.HttpPort != 9000 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
+
+func TestReadConfigIncludeKey(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "common.cfg"), "[build]\ncc = gcc\n")
+	filename := filepath.Join(dir, "build.cfg")
+	writeFile(t, filename, "include = common.cfg\n\n[build]\ninclude = /usr/include\n\n[DEFAULT]\n")
+
+	var c struct {
+		Build struct {
+			CC      string `mapstructure:"cc"`
+			Include string `mapstructure:"include"`
+		} `mapstructure:"build"`
+	}
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Build.CC != "gcc" || c.Build.Include != "/usr/include" {
+		t.Fatalf("unexpected config %+v", c.Build)
+	}
+}
+
+func TestReadConfigIncludeWithoutFile(t *testing.T) {
+	data := []byte("!include /etc/hostname\n")
+	var c includeConfig
+	if _, err := ReadConfigBytes(&c, data, "cfg"); !errors.Is(err, ErrInclude) || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("err = %v, want ErrInclude", err)
+	}
+	if _, err := ReadProvider(&c, NewMemoryProvider([]byte("include = secret.cfg\n"), "ini")); !errors.Is(err, ErrInclude) {
+		t.Fatalf("err = %v, want ErrInclude", err)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// iniSection 判断一行是否为 [section] 并返回 section 名称
+func iniSection(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) < 2 || line[0] != '[' {
+		return "", false
+	}
+
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return "", false
+	}
+
+	return strings.TrimSpace(line[1:end]), true
+}
+
+// iniComment 判断一行是否为空行或者注释
+func iniComment(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || line[0] == ';' || line[0] == '#'
+}
+
+// iniKeyValue 解析 key = value 形式的一行，value 中的行尾注释不做处理
+func iniKeyValue(line string) (key, value string, ok bool) {
+	if iniComment(line) {
+		return "", "", false
+	}
+	if _, isSection := iniSection(line); isSection {
+		return "", "", false
+	}
+
+	i := strings.IndexAny(line, "=:")
+	if i < 0 {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+}
+
+// DuplicateKey ini/cfg 文件中同一个 section 下重复出现的 key，Line 和 First 从 1 开始
+type DuplicateKey struct {
+	Section string
+	Key     string
+	Line    int
+	First   int
+}
+
+func (d DuplicateKey) String() string {
+	return fmt.Sprintf("line %d: duplicate key %q in section [%s], first defined at line %d", d.Line, d.Key, d.Section, d.First)
+}
+
+// DuplicateKeys 查找 ini/cfg 内容中同一个 section 下重复出现的 key，section 和 key 不区分大小写，
+// 同名的 section 出现多次时视为同一个 section，不展开 include 指令
+func DuplicateKeys(data []byte) []DuplicateKey {
+	return duplicateKeys(data, strings.ToLower)
+}
+
+// DuplicateKeysCaseSensitive 与 DuplicateKeys 一致，section 和 key 区分大小写，用于 WithCaseSensitive 读取的文件，
+// 例如 Port 和 port 不是重复的 key
+func DuplicateKeysCaseSensitive(data []byte) []DuplicateKey {
+	return duplicateKeys(data, func(s string) string { return s })
+}
+
+func duplicateKeys(data []byte, normalize func(string) string) []DuplicateKey {
+	var (
+		dups    []DuplicateKey
+		section string
+		seen    = make(map[string]int)
+	)
+	for i, line := range strings.Split(string(data), "\n") {
+		if s, ok := iniSection(line); ok {
+			section = s
+			continue
+		}
+
+		key, _, ok := iniKeyValue(line)
+		if !ok {
+			continue
+		}
+		if _, ok := includeDirective(line, section); ok {
+			continue
+		}
+
+		id := normalize(section) + "\x00" + normalize(key)
+		if first, ok := seen[id]; ok {
+			dups = append(dups, DuplicateKey{Section: section, Key: key, Line: i + 1, First: first})
+			continue
+		}
+		seen[id] = i + 1
+	}
+
+	return dups
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestDuplicateKeys(t *testing.T) {
+	data := []byte("app_mode = dev\ninclude = a.cfg\ninclude = b.cfg\n\n[server]\nname = a\nhttp_port = 1\n\n[db]\nname = db\n\n[Server]\nNAME = b ; 覆盖\n")
+
+	dups := DuplicateKeys(data)
+	if len(dups) != 1 {
+		t.Fatalf("dups = %v", dups)
+	}
+	if d := dups[0]; d.Section != "Server" || d.Key != "NAME" || d.Line != 13 || d.First != 6 {
+		t.Fatalf("unexpected duplicate %+v", d)
+	}
+
+	data = []byte("[DB]\nPort = 3306\nport = 3307\n\n[db]\nport = 1\n\n[DB]\nport = 3308\n")
+	if dups := DuplicateKeys(data); len(dups) != 3 {
+		t.Fatalf("dups = %v", dups)
+	}
+	dups = DuplicateKeysCaseSensitive(data)
+	if len(dups) != 1 {
+		t.Fatalf("dups = %v", dups)
+	}
+	if d := dups[0]; d.Section != "DB" || d.Key != "port" || d.Line != 9 || d.First != 3 {
+		t.Fatalf("unexpected duplicate %+v", d)
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrRefNotFound Resolver 找不到引用的值时返回该错误，此时如果引用中声明了默认值则使用默认值
+var ErrRefNotFound = errors.New("reference not found")
+
+// Resolver 解析配置值中 ${scheme:ref} 形式的引用，ref 为去除 scheme 和默认值后的部分
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc 函数形式的 Resolver
+type ResolverFunc func(ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// EnvResolver 读取环境变量，${env:DB_PASS}
+func EnvResolver() Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		if v, ok := os.LookupEnv(ref); ok {
+			return v, nil
+		}
+
+		return "", ErrRefNotFound
+	})
+}
+
+// FileResolver 读取文件内容并去除末尾的换行符，${file:/run/secrets/db}
+func FileResolver() Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		buf, err := os.ReadFile(ref)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", errors.Join(ErrRefNotFound, err)
+			}
+			return "", err
+		}
+
+		return strings.TrimRight(string(buf), "\r\n"), nil
+	})
+}
+
+func defaultResolvers() map[string]Resolver {
+	return map[string]Resolver{
+		"env":  EnvResolver(),
+		"file": FileResolver(),
+	}
+}
+
+// interpolate 解析 v 中所有字符串值里的 ${scheme:ref} 以及 ${scheme:ref:-default} 引用，
+// 未注册的 scheme 保持原样，$${ 转义为 ${
+func interpolate(v store, resolvers map[string]Resolver) error {
+	var errs []error
+	for _, key := range v.AllKeys() {
+		value, changed, err := interpolateValue(v.Get(key), resolvers)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", key, err))
+			continue
+		}
+		if changed {
+			v.Set(key, value)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(ErrResolve, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func interpolateValue(value any, resolvers map[string]Resolver) (any, bool, error) {
+	switch val := value.(type) {
+	case string:
+		s, err := interpolateString(unprotectRefs(val), resolvers)
+		return s, s != val, err
+	case []any:
+		changed := false
+		for i, item := range val {
+			s, c, err := interpolateValue(item, resolvers)
+			if err != nil {
+				return nil, false, err
+			}
+			val[i], changed = s, changed || c
+		}
+		return val, changed, nil
+	case map[string]any:
+		changed := false
+		for k, item := range val {
+			s, c, err := interpolateValue(item, resolvers)
+			if err != nil {
+				return nil, false, err
+			}
+			val[k], changed = s, changed || c
+		}
+		return val, changed, nil
+	}
+
+	return value, false, nil
+}
+
+func interpolateString(s string, resolvers map[string]Resolver) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		// $${ 为转义，输出 ${
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i])
+			b.WriteString("{")
+			s = s[i+2:]
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		ref := s[i+2 : i+end]
+		scheme, rest, ok := strings.Cut(ref, ":")
+		r, registered := resolvers[scheme]
+		if !ok || !registered {
+			b.WriteString(s[:i+end+1])
+			s = s[i+end+1:]
+			continue
+		}
+
+		name, def, hasDefault := strings.Cut(rest, ":-")
+		resolved, err := r.Resolve(name)
+		if err != nil {
+			if !errors.Is(err, ErrRefNotFound) || !hasDefault {
+				return "", fmt.Errorf("resolve ${%s}: %w", ref, err)
+			}
+			resolved = def
+		}
+
+		b.WriteString(s[:i])
+		b.WriteString(resolved)
+		s = s[i+end+1:]
+	}
+}
+
+// properties 格式在读取时会把 ${key} 当作对其它 key 的引用进行展开，找不到时替换为空字符串，
+// 这里在解析前将已注册 scheme 的引用替换为占位符，解析后再还原
+const protectedRef = "$\ue000{"
+
+var refPrefix = regexp.MustCompile(`\$\{(\w+):`)
+
+func protectRefs(data []byte, resolvers map[string]Resolver) []byte {
+	return refPrefix.ReplaceAllFunc(data, func(m []byte) []byte {
+		scheme := string(m[2 : len(m)-1])
+		if _, ok := resolvers[scheme]; !ok {
+			return m
+		}
+		return append([]byte(protectedRef), m[2:]...)
+	})
+}
+
+func unprotectRefs(s string) string {
+	return strings.ReplaceAll(s, protectedRef, "${")
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigInterpolate(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db")
+	writeFile(t, secret, "s3cret\n")
+	t.Setenv("DB_USER", "root")
+
+	files := map[string]string{
+		"server.cfg":        "[db]\nuser = ${env:DB_USER}\npassword = ${file:" + secret + "}\nport = ${env:DB_PORT:-3306}\nvault = ${vault:db/pass}\nraw = $${env:DB_USER}\n",
+		"server.yaml":       "db:\n  user: ${env:DB_USER}\n  password: ${file:" + secret + "}\n  port: ${env:DB_PORT:-3306}\n  vault: ${vault:db/pass}\n  raw: $${env:DB_USER}\n",
+		"server.toml":       "[db]\nuser = \"${env:DB_USER}\"\npassword = \"${file:" + secret + "}\"\nport = \"${env:DB_PORT:-3306}\"\nvault = \"${vault:db/pass}\"\nraw = \"$${env:DB_USER}\"\n",
+		"server.json":       `{"db": {"user": "${env:DB_USER}", "password": "${file:` + secret + `}", "port": "${env:DB_PORT:-3306}", "vault": "${vault:db/pass}", "raw": "$${env:DB_USER}"}}`,
+		"server.properties": "db.user = ${env:DB_USER}\ndb.password = ${file:" + secret + "}\ndb.port = ${env:DB_PORT:-3306}\ndb.vault = ${vault:db/pass}\ndb.raw = $${env:DB_USER}\n",
+	}
+
+	vault := ResolverFunc(func(ref string) (string, error) {
+		return "from-" + ref, nil
+	})
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(dir, name)
+			writeFile(t, filename, content)
+
+			var c struct {
+				DB struct {
+					User     string `mapstructure:"user"`
+					Password string `mapstructure:"password"`
+					Port     int    `mapstructure:"port"`
+					Vault    string `mapstructure:"vault"`
+					Raw      string `mapstructure:"raw"`
+				} `mapstructure:"db"`
+			}
+			if _, err := ReadConfig(&c, filename, WithResolver("vault", vault)); err != nil {
+				t.Fatal(err)
+			}
+
+			if c.DB.User != "root" || c.DB.Password != "s3cret" || c.DB.Port != 3306 || c.DB.Vault != "from-db/pass" || c.DB.Raw != "${env:DB_USER}" {
+				t.Fatalf("unexpected config %+v", c.DB)
+			}
+		})
+	}
+}
+
+func TestReadConfigInterpolateMissing(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[db]\npassword = ${env:CONFIG_TEST_MISSING}\n")
+
+	var c struct{}
+	_, err := ReadConfig(&c, filename)
+	if !errors.Is(err, ErrResolve) || !errors.Is(err, ErrRefNotFound) || !strings.Contains(err.Error(), `key "db::password"`) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// ReadLayered 按顺序读取多个配置文件并进行深度合并，后面的文件覆盖前面文件中相同的 key，
+// 例如 base.yaml, prod.cfg, local.yaml。每个文件按照自己的后缀判断类型，因此支持混合格式。
+// 只要有一个文件为 cfg 格式，合并后的 key 分割符即为 ::。
+// 返回的 sources 记录了最终每个 key 的值来自哪一个文件，key 使用合并后的分割符展开。
+// 与 ReadConfig 一致会合并 APP_MODE 或者 app_mode 对应的 profile，
+// 并解密 ENC[AES256-GCM,...] 形式的值，密钥从 CONFIG_KEY 或者 CONFIG_KEY_FILE 环境变量读取
+func ReadLayered(config any, files ...string) (v *viper.Viper, sources map[string]string, err error) {
+	o := newOptions(nil)
+	delim := "."
+	stores := make([]store, 0, len(files))
+	fileTypes := make([]string, 0, len(files))
+	for _, filename := range files {
+		if _, err := os.Stat(filename); err != nil {
+			return nil, nil, err
+		}
+
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, nil, errors.Join(ErrReadInConfig, err)
+		}
+
+		fileType, err := configType(filename, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		if fileType == "cfg" {
+			delim = keyDelimiter(fileType)
+		}
+
+		lv, err := parse(data, fileType, keyDelimiter(fileType), &includer{name: filename}, o.resolvers)
+		if err != nil {
+			return nil, nil, err
+		}
+		stores = append(stores, lv)
+		fileTypes = append(fileTypes, fileType)
+	}
+
+	// profile 根据所有文件合并后的 app_mode 确定，然后分别合并每个文件中的 [section@profile] 或者 profiles.<profile>
+	raw := make(map[string]any)
+	for _, lv := range stores {
+		mergeMaps(raw, lv.AllSettings())
+	}
+	o.profileName = o.profile(raw)
+
+	layers := make([]map[string]any, 0, len(stores))
+	for i, lv := range stores {
+		pv, err := o.applyProfile(lv, keyDelimiter(fileTypes[i]), fileTypes[i])
+		if err != nil {
+			return nil, nil, errors.Join(ErrReadInConfig, err)
+		}
+		layers = append(layers, pv.AllSettings())
+	}
+
+	merged := make(map[string]any)
+	sources = make(map[string]string)
+	for i, layer := range layers {
+		mergeMaps(merged, layer)
+
+		flat := make(map[string]any)
+		flattenMap(layer, "", delim, flat)
+		for key := range flat {
+			sources[key] = files[i]
+		}
+	}
+
+	// 后面的文件可能把前面文件中的一个 map 替换为普通的值，这里只保留最终存在的 key
+	final := make(map[string]any)
+	flattenMap(merged, "", delim, final)
+	for key := range sources {
+		if _, ok := final[key]; !ok {
+			delete(sources, key)
+		}
+	}
+
+	v = viper.NewWithOptions(viper.KeyDelimiter(delim))
+	if err := v.MergeConfigMap(merged); err != nil {
+		return nil, nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	applyDefaults(v, config, delim, foldMatch)
+
+	if err := interpolate(v, o.resolvers); err != nil {
+		return nil, nil, err
+	}
+
+	if err := o.decrypt(v); err != nil {
+		return nil, nil, err
+	}
+
+	if err := unmarshal(v, config); err != nil {
+		return nil, nil, errors.Join(ErrUnmarshal, err)
+	}
+
+	if err := validate(config, delim); err != nil {
+		return nil, nil, err
+	}
+
+	return v, sources, nil
+}
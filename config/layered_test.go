@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadLayered(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.cfg")
+	local := filepath.Join(dir, "local.json")
+	writeFile(t, base, "server:\n  name: base\n  http_port: 8080\n  debug: true\ndb:\n  host: localhost\n")
+	writeFile(t, prod, "[server]\nhttp_port = 10000\ntrace.addr = http://0.0.0.0:7820\n")
+	writeFile(t, local, `{"server": {"debug": false}}`)
+
+	var c struct {
+		Server struct {
+			Name      string `mapstructure:"name"`
+			HttpPort  int    `mapstructure:"http_port"`
+			Debug     bool   `mapstructure:"debug"`
+			TraceAddr string `mapstructure:"trace.addr"`
+		} `mapstructure:"server"`
+		DB struct {
+			Host string `mapstructure:"host"`
+		} `mapstructure:"db"`
+	}
+
+	v, sources, err := ReadLayered(&c, base, prod, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Server.Name != "base" || c.Server.HttpPort != 10000 || c.Server.Debug || c.Server.TraceAddr != "http://0.0.0.0:7820" || c.DB.Host != "localhost" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	if got := v.GetInt("server::http_port"); got != 10000 {
+		t.Fatalf("server::http_port = %d", got)
+	}
+
+	want := map[string]string{
+		"server::name":       base,
+		"server::http_port":  prod,
+		"server::debug":      local,
+		"server::trace.addr": prod,
+		"db::host":           base,
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("sources = %v", sources)
+	}
+	for k, f := range want {
+		if sources[k] != f {
+			t.Errorf("source of %s = %q, want %q", k, sources[k], f)
+		}
+	}
+}
+
+func TestReadLayeredDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	s, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ParseKey(s)
+	password, err := Encrypt(key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.cfg")
+	writeFile(t, base, "db:\n  user: root\n")
+	writeFile(t, prod, "[db]\npassword = "+password+"\n")
+
+	var c secretConfig
+	t.Setenv(KeyEnv, "")
+	t.Setenv(KeyFileEnv, "")
+	if _, _, err := ReadLayered(&c, base, prod); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("err = %v, want ErrDecrypt", err)
+	}
+
+	t.Setenv(KeyEnv, s)
+	v, _, err := ReadLayered(&c, base, prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DB.User != "root" || c.DB.Password != "s3cret" || v.GetString("db::password") != "s3cret" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
+
+func TestReadLayeredProfile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.cfg")
+	local := filepath.Join(dir, "local.yaml")
+	writeFile(t, base, "[server]\nport = 1\nname = base\n\n[server@prod]\nport = 2\n\n[server@dev]\nport = 3\n")
+	writeFile(t, local, "profiles:\n  prod:\n    server:\n      name: prod\n")
+
+	var c struct {
+		Server struct {
+			Port int    `mapstructure:"port"`
+			Name string `mapstructure:"name"`
+		} `mapstructure:"server"`
+	}
+
+	t.Setenv(profileEnv, "prod")
+	v, sources, err := ReadLayered(&c, base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != 2 || c.Server.Name != "prod" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if v.IsSet("server@prod") || v.IsSet("server@dev") {
+		t.Fatalf("profile sections left in result: %v", v.AllKeys())
+	}
+	want := map[string]string{"server::port": base, "server::name": local}
+	if len(sources) != len(want) || sources["server::port"] != base || sources["server::name"] != local {
+		t.Fatalf("sources = %v, want %v", sources, want)
+	}
+
+	// app_mode 可以写在任意一个文件中
+	t.Setenv(profileEnv, "")
+	writeFile(t, local, "app_mode: dev\n")
+	if _, _, err := ReadLayered(&c, base, local); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != 3 || c.Server.Name != "base" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
@@ -0,0 +1,14 @@
+package config
+
+// Load 读取配置文件并解析到新创建的 T 类型结构体上，文件类型、严格模式、分割符等通过 opts 设置，
+// 支持的文件类型以及返回的错误与 ReadConfig 一致
+//
+//	c, err := config.Load[Config]("server.cfg", config.WithStrict())
+func Load[T any](filename string, opts ...Option) (*T, error) {
+	config := new(T)
+	if _, err := ReadConfig(config, filename, opts...); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
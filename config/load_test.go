@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "server.conf")
+	writeFile(t, filename, "[server]\nname = test\nhttp_port = 10000\ntrace.addr = http://0.0.0.0:7820\n")
+
+	type config struct {
+		Server struct {
+			Name      string `mapstructure:"name"`
+			HttpPort  int    `mapstructure:"http_port"`
+			TraceAddr string `mapstructure:"trace.addr"`
+		} `mapstructure:"server"`
+	}
+
+	c, err := Load[config](filename, WithFormat("cfg"), WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "test" || c.Server.HttpPort != 10000 || c.Server.TraceAddr != "http://0.0.0.0:7820" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	if _, err := Load[config](filename, WithFormat("xml")); !errors.Is(err, ErrFileTypeNotAllow) {
+		t.Fatalf("err = %v, want ErrFileTypeNotAllow", err)
+	}
+
+	yaml := filepath.Join(dir, "server.yaml")
+	writeFile(t, yaml, "server:\n  name: test\n  trace.addr: http://0.0.0.0:7820\n")
+	c, err = Load[config](yaml, WithDelimiter("::"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.TraceAddr != "http://0.0.0.0:7820" {
+		t.Fatalf("trace.addr = %q", c.Server.TraceAddr)
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+// mergeMaps 将 src 深度合并到 dst 中，两边都是 map 的时候递归合并，否则 src 覆盖 dst
+func mergeMaps(dst, src map[string]any) {
+	for k, sv := range src {
+		if sm, ok := sv.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeMaps(dm, sm)
+				continue
+			}
+			dm := make(map[string]any, len(sm))
+			mergeMaps(dm, sm)
+			dst[k] = dm
+			continue
+		}
+		dst[k] = sv
+	}
+}
+
+// flattenMap 将嵌套的 map 按照分割符展开为一层，只保留叶子节点
+func flattenMap(m map[string]any, prefix, delim string, out map[string]any) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + delim + k
+		}
+
+		if sub, ok := v.(map[string]any); ok {
+			flattenMap(sub, key, delim, out)
+			continue
+		}
+		out[key] = v
+	}
+}
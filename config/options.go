@@ -0,0 +1,346 @@
+package config
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pemako/gopkg/envload"
+	"github.com/spf13/pflag"
+)
+
+// Option 配置项，和仓库中其它包保持一致采用 Name/Value 的形式
+type Option interface {
+	Name() string
+	Value() any
+}
+
+type option struct {
+	name  string
+	value any
+}
+
+func (o *option) Name() string {
+	return o.name
+}
+
+func (o *option) Value() any {
+	return o.value
+}
+
+const (
+	optkeyDebounce      = "debounce"
+	optkeyErrorHandler  = "error-handler"
+	optkeyEnvPrefix     = "env-prefix"
+	optkeyEnvLoader     = "env-loader"
+	optkeyResolver      = "resolver"
+	optkeyStrict        = "strict"
+	optkeyStrictFields  = "strict-fields"
+	optkeyFormat        = "format"
+	optkeyDelimiter     = "delimiter"
+	optkeyProfile       = "profile"
+	optkeyKey           = "key"
+	optkeyKeyFile       = "key-file"
+	optkeyHTTPClient    = "http-client"
+	optkeyHeader        = "header"
+	optkeyPollInterval  = "poll-interval"
+	optkeyCaseSensitive = "case-sensitive"
+	optkeyHistory       = "history"
+	optkeyHealthCheck   = "health-check"
+	optkeyFlags         = "flags"
+)
+
+// 默认的文件事件合并间隔，编辑器保存文件时通常会连续产生多个事件
+const defaultDebounce = 100 * time.Millisecond
+
+// Watcher 默认保留的历史版本数量
+const defaultHistory = 10
+
+type options struct {
+	debounce      time.Duration
+	errorHandler  func(error)
+	envEnabled    bool
+	envPrefix     string
+	envLoader     *envload.Loader
+	resolvers     map[string]Resolver
+	strict        bool
+	strictFields  bool
+	format        string
+	delim         string
+	profileName   string
+	encKey        []byte
+	keyFile       string
+	httpClient    *http.Client
+	header        http.Header
+	pollInterval  time.Duration
+	caseSensitive bool
+	history       int
+	healthCheck   func(any) error
+	flagValues    func() map[string]string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		debounce:     defaultDebounce,
+		errorHandler: func(error) {},
+		resolvers:    defaultResolvers(),
+		history:      defaultHistory,
+	}
+
+	for _, opt := range opts {
+		switch opt.Name() {
+		case optkeyDebounce:
+			o.debounce = opt.Value().(time.Duration)
+		case optkeyErrorHandler:
+			if fn := opt.Value().(func(error)); fn != nil {
+				o.errorHandler = fn
+			}
+		case optkeyEnvPrefix:
+			o.envEnabled = true
+			o.envPrefix = opt.Value().(string)
+		case optkeyEnvLoader:
+			o.envLoader = opt.Value().(*envload.Loader)
+		case optkeyResolver:
+			r := opt.Value().(*resolverPair)
+			o.resolvers[r.scheme] = r.resolver
+		case optkeyStrict:
+			o.strict = opt.Value().(bool)
+		case optkeyStrictFields:
+			o.strictFields = opt.Value().(bool)
+			o.strict = o.strict || o.strictFields
+		case optkeyFormat:
+			o.format = strings.ToLower(opt.Value().(string))
+		case optkeyDelimiter:
+			o.delim = opt.Value().(string)
+		case optkeyProfile:
+			o.profileName = opt.Value().(string)
+		case optkeyKey:
+			o.encKey = opt.Value().([]byte)
+		case optkeyKeyFile:
+			o.keyFile = opt.Value().(string)
+		case optkeyHTTPClient:
+			o.httpClient = opt.Value().(*http.Client)
+		case optkeyHeader:
+			h := opt.Value().([2]string)
+			if o.header == nil {
+				o.header = make(http.Header)
+			}
+			o.header.Add(h[0], h[1])
+		case optkeyPollInterval:
+			o.pollInterval = opt.Value().(time.Duration)
+		case optkeyCaseSensitive:
+			o.caseSensitive = opt.Value().(bool)
+		case optkeyHistory:
+			if n := opt.Value().(int); n > 0 {
+				o.history = n
+			}
+		case optkeyHealthCheck:
+			o.healthCheck = opt.Value().(func(any) error)
+		case optkeyFlags:
+			o.flagValues = opt.Value().(func() map[string]string)
+		}
+	}
+
+	return o
+}
+
+// configType 获取配置文件的类型，设置了 WithFormat 时使用设置的类型
+func (o *options) configType(filename string, data []byte) (string, error) {
+	if o.format == "" {
+		return configType(filename, data)
+	}
+
+	if !allowType(o.format) {
+		return "", ErrFileTypeNotAllow
+	}
+
+	return o.format, nil
+}
+
+// keyDelimiter 获取 key 分割符，设置了 WithDelimiter 时使用设置的分割符
+func (o *options) keyDelimiter(fileType string) string {
+	if o.delim != "" {
+		return o.delim
+	}
+
+	return keyDelimiter(fileType)
+}
+
+// WithDebounce 设置 Watch 合并文件变更事件的时间间隔，默认 100ms
+func WithDebounce(d time.Duration) Option {
+	return &option{
+		name:  optkeyDebounce,
+		value: d,
+	}
+}
+
+// WithErrorHandler 设置 Watch 重新加载配置失败时的回调，失败时会继续保留上一次成功解析的值
+func WithErrorHandler(fn func(error)) Option {
+	return &option{
+		name:  optkeyErrorHandler,
+		value: fn,
+	}
+}
+
+// WithEnvPrefix 开启环境变量覆盖配置文件中的值，只处理以 prefix 开头的环境变量，
+// 去除前缀后使用双下划线 __ 分割层级并转为小写，例如 prefix 为 APP 时
+// APP_SERVER__HTTP_PORT 对应 cfg 文件中的 server::http_port，其它格式文件中的 server.http_port
+func WithEnvPrefix(prefix string) Option {
+	return &option{
+		name:  optkeyEnvPrefix,
+		value: prefix,
+	}
+}
+
+// WithEnvLoader 设置读取环境变量使用的 envload.Loader，默认使用 envload.New()，
+// 即当前进程的环境变量以及 ENVDIR 目录中的变量
+func WithEnvLoader(l *envload.Loader) Option {
+	return &option{
+		name:  optkeyEnvLoader,
+		value: l,
+	}
+}
+
+type resolverPair struct {
+	scheme   string
+	resolver Resolver
+}
+
+// WithResolver 注册 ${scheme:ref} 引用的解析器，默认支持 env 和 file，相同的 scheme 会覆盖默认的解析器
+func WithResolver(scheme string, r Resolver) Option {
+	return &option{
+		name: optkeyResolver,
+		value: &resolverPair{
+			scheme:   scheme,
+			resolver: r,
+		},
+	}
+}
+
+// WithStrict 开启严格模式，配置文件中存在无法映射到结构体上的 key 时返回 ErrStrict 错误，
+// 用于发现配置文件中拼写错误的 key，例如 http_prot
+func WithStrict() Option {
+	return &option{
+		name:  optkeyStrict,
+		value: true,
+	}
+}
+
+// WithStrictFields 在 WithStrict 的基础上同时检查结构体中没有在配置文件中出现的字段，声明了 default tag 的字段除外
+func WithStrictFields() Option {
+	return &option{
+		name:  optkeyStrictFields,
+		value: true,
+	}
+}
+
+// WithFormat 指定配置文件的类型，不再根据文件后缀判断，例如 WithFormat("cfg")
+func WithFormat(format string) Option {
+	return &option{
+		name:  optkeyFormat,
+		value: format,
+	}
+}
+
+// WithDelimiter 指定 key 的分割符，默认 cfg 格式为 ::，其它格式为 .
+func WithDelimiter(delim string) Option {
+	return &option{
+		name:  optkeyDelimiter,
+		value: delim,
+	}
+}
+
+// WithProfile 设置需要合并的 profile，例如 prod 时 [server@prod] 中的值会覆盖 [server] 中的值，
+// 没有设置时依次读取 APP_MODE 环境变量和配置文件中的 app_mode
+func WithProfile(name string) Option {
+	return &option{
+		name:  optkeyProfile,
+		value: name,
+	}
+}
+
+// WithKey 设置解密 ENC[AES256-GCM,...] 形式的值使用的 32 字节密钥
+func WithKey(key []byte) Option {
+	return &option{
+		name:  optkeyKey,
+		value: key,
+	}
+}
+
+// WithKeyFile 设置解密使用的密钥文件，文件内容为 GenerateKey 生成的 base64 字符串，
+// 没有设置 WithKey 和 WithKeyFile 时依次读取 CONFIG_KEY 和 CONFIG_KEY_FILE 环境变量
+func WithKeyFile(filename string) Option {
+	return &option{
+		name:  optkeyKeyFile,
+		value: filename,
+	}
+}
+
+// WithHTTPClient 设置 HTTPProvider 使用的 http.Client，默认为 http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return &option{
+		name:  optkeyHTTPClient,
+		value: client,
+	}
+}
+
+// WithHeader 设置 HTTPProvider 请求时携带的 header，例如鉴权使用的 token，可以设置多次
+func WithHeader(key, value string) Option {
+	return &option{
+		name:  optkeyHeader,
+		value: [2]string{key, value},
+	}
+}
+
+// WithPollInterval 设置 HTTPProvider 轮询配置变化的时间间隔，默认 30s
+func WithPollInterval(d time.Duration) Option {
+	return &option{
+		name:  optkeyPollInterval,
+		value: d,
+	}
+}
+
+// WithCaseSensitive 开启区分大小写模式，解析时保留 key 原始的大小写(包括 map 的 key)，
+// 结构体字段只匹配大小写完全一致的 key，例如 cfg 文件中的 Port 和 port 为不同的 key。
+// 该模式下不使用 viper 解析配置内容，返回的 viper 实例中的 key 仍然不区分大小写，需要通过结构体获取值，
+// 只有大小写不同的 key 在 viper 中保留按字节排序的第一个，例如 Port 和 port 保留 Port 的值
+func WithCaseSensitive() Option {
+	return &option{
+		name:  optkeyCaseSensitive,
+		value: true,
+	}
+}
+
+// WithHistory 设置 Watcher 保留的历史版本数量(包含当前版本)，默认 10
+func WithHistory(n int) Option {
+	return &option{
+		name:  optkeyHistory,
+		value: n,
+	}
+}
+
+// WithHealthCheck 设置 Watcher 重新加载后的健康检查，fn 在 onChange 之后调用，
+// 返回错误时回滚到上一个版本(再次回调 onChange)并通过 WithErrorHandler 上报 ErrUnhealthy
+func WithHealthCheck(fn func(value any) error) Option {
+	return &option{
+		name:  optkeyHealthCheck,
+		value: fn,
+	}
+}
+
+// WithFlags 使用 fs 中命令行指定的参数覆盖配置，fs 中的参数通过 BindFlags 注册，需要在 ReadConfig 之前解析
+func WithFlags(fs *pflag.FlagSet) Option {
+	return &option{
+		name:  optkeyFlags,
+		value: pflagValues(fs),
+	}
+}
+
+// WithGoFlags 与 WithFlags 一致，使用标准库的 flag，fs 中的参数通过 BindGoFlags 注册
+func WithGoFlags(fs *flag.FlagSet) Option {
+	return &option{
+		name:  optkeyFlags,
+		value: goFlagValues(fs),
+	}
+}
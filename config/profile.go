@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// profileEnv 没有设置 WithProfile 时读取该环境变量作为 profile
+	profileEnv = "APP_MODE"
+	// profileKey 环境变量也没有设置时读取配置文件中第一层(ini/cfg 为 default section)的 app_mode
+	profileKey = "app_mode"
+	// profileSep section 名称和 profile 之间的分割符，例如 [server@prod]
+	profileSep = "@"
+	// profilesKey yaml/json/toml 等格式中存放各个 profile 的 key，例如 profiles.prod.server.http_port
+	profilesKey = "profiles"
+)
+
+// profileSection ini/cfg 中 profile 对应的 section 名称，profile 只能包含字母、数字、_ 和 -
+var profileSection = regexp.MustCompile(`^([^@]+)@([A-Za-z0-9_-]+)$`)
+
+// profile 获取需要合并的 profile，优先级 WithProfile > APP_MODE 环境变量 > 配置文件中的 app_mode
+func (o *options) profile(settings map[string]any) string {
+	if o.profileName != "" {
+		return o.profileName
+	}
+
+	if mode, ok := os.LookupEnv(profileEnv); ok && mode != "" {
+		return mode
+	}
+
+	// viper 返回的 key 都是小写的
+	if mode, ok := settings[profileKey].(string); ok {
+		return mode
+	}
+	if def, ok := settings["default"].(map[string]any); ok {
+		if mode, ok := def[profileKey].(string); ok {
+			return mode
+		}
+	}
+
+	return ""
+}
+
+// applyProfile 将 profile 对应的 [section@profile](ini/cfg) 或者 profiles.<profile>(其它格式) 合并到基础配置上，
+// 其它 profile 的配置会被移除，配置中不包含 profile 时直接返回 v。
+// 不是 section 或者 @ 后面不是合法 profile 名称的 key(例如 yaml 中的 admin@example.com)以及子项不全是 map 的 profiles 保持不变
+func (o *options) applyProfile(v store, delim, fileType string) (store, error) {
+	settings := v.AllSettings()
+	profile := o.profile(settings)
+	sections := fileType == "ini" || fileType == "cfg"
+
+	base := make(map[string]any, len(settings))
+	overlays := make(map[string]any)
+	found := false
+	for k, value := range settings {
+		if !sections && k == profilesKey {
+			if profiles, ok := value.(map[string]any); ok && allMaps(profiles) {
+				found = true
+				if _, value, ok := lookupKey(profiles, profile, foldMatch); ok && profile != "" {
+					mergeMaps(overlays, value.(map[string]any))
+				}
+				continue
+			}
+		}
+
+		m := profileSection.FindStringSubmatch(k)
+		if _, isSection := value.(map[string]any); !sections || !isSection || m == nil {
+			base[k] = value
+			continue
+		}
+
+		found = true
+		if strings.EqualFold(m[2], profile) {
+			mergeMaps(overlays, map[string]any{m[1]: value})
+		}
+	}
+
+	if !found {
+		return v, nil
+	}
+
+	mergeMaps(base, overlays)
+
+	if _, ok := v.(*caseStore); ok {
+		return newCaseStore(base, delim), nil
+	}
+
+	nv := viper.NewWithOptions(viper.KeyDelimiter(delim))
+	if err := nv.MergeConfigMap(base); err != nil {
+		return nil, err
+	}
+
+	return nv, nil
+}
+
+// allMaps 判断 m 中的值是否都是 map
+func allMaps(m map[string]any) bool {
+	for _, value := range m {
+		if _, ok := value.(map[string]any); !ok {
+			return false
+		}
+	}
+
+	return true
+}
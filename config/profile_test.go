@@ -0,0 +1,136 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type profileConfig struct {
+	Server struct {
+		Name     string `mapstructure:"name"`
+		HttpPort int    `mapstructure:"http_port"`
+		Debug    bool   `mapstructure:"debug"`
+	} `mapstructure:"server"`
+}
+
+const profileCfg = `
+[server]
+name = base
+http_port = 8080
+debug = true
+
+[server@prod]
+http_port = 80
+debug = false
+
+[server@dev]
+http_port = 10000
+`
+
+func TestReadConfigProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, profileCfg)
+
+	var c profileConfig
+	v, err := ReadConfig(&c, filename, WithProfile("prod"), WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "base" || c.Server.HttpPort != 80 || c.Server.Debug {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if v.IsSet("server@dev::http_port") {
+		t.Fatal("server@dev should be removed")
+	}
+
+	t.Setenv("APP_MODE", "dev")
+	c = profileConfig{}
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.HttpPort != 10000 || !c.Server.Debug {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	t.Setenv("APP_MODE", "")
+	c = profileConfig{}
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.HttpPort != 8080 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
+
+func TestReadConfigProfileAppMode(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "app_mode = prod\n"+profileCfg)
+
+	var c profileConfig
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.HttpPort != 80 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
+
+func TestReadConfigProfileYAML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.yaml")
+	writeFile(t, filename, `
+server:
+  name: base
+  http_port: 8080
+profiles:
+  prod:
+    server:
+      http_port: 80
+  dev:
+    server:
+      name: dev
+`)
+
+	var c profileConfig
+	v, err := ReadConfig(&c, filename, WithProfile("prod"), WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "base" || c.Server.HttpPort != 80 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+	if v.IsSet("profiles") {
+		t.Fatal("profiles should be removed")
+	}
+}
+
+func TestReadConfigProfileUntouchedKeys(t *testing.T) {
+	dir := t.TempDir()
+	yaml := filepath.Join(dir, "users.yaml")
+	writeFile(t, yaml, "team@prod: x\nserver@prod:\n  name: y\nprofiles:\n  - a\n  - b\n")
+
+	var m map[string]any
+	if _, err := ReadConfig(&m, yaml, WithProfile("prod")); err != nil {
+		t.Fatal(err)
+	}
+	if m["team@prod"] != "x" || m["server@prod"] == nil || len(m["profiles"].([]any)) != 2 {
+		t.Fatalf("keys should be left untouched: %v", m)
+	}
+
+	// @ 后面不是合法的 profile 名称时不是 profile section
+	cfg := filepath.Join(dir, "mail.cfg")
+	writeFile(t, cfg, "[mail]\nfrom = a\n\n[admin@example.com]\nname = b\n\n[mail@prod]\nfrom = c\n")
+	var c struct {
+		Mail struct {
+			From string `mapstructure:"from"`
+		} `mapstructure:"mail"`
+		Admin struct {
+			Name string `mapstructure:"name"`
+		} `mapstructure:"admin@example.com"`
+	}
+	if _, err := ReadConfig(&c, cfg, WithProfile("prod")); err != nil {
+		t.Fatal(err)
+	}
+	if c.Mail.From != "c" || c.Admin.Name != "b" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
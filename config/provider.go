@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Provider 配置内容的来源，Read 返回配置内容以及类型，类型的规则与文件后缀一致，为空时根据内容判断
+type Provider interface {
+	Read() (data []byte, format string, err error)
+}
+
+// Watchable 支持监听变化的 Provider，Watch 不阻塞，内容变化时调用 notify(nil)，
+// 监听出错时调用 notify(err)，ctx 结束后停止监听
+type Watchable interface {
+	Watch(ctx context.Context, notify func(error)) error
+}
+
+// ReadProvider 读取 p 中的配置内容并解析到 config 上，设置了 WithFormat 时忽略 p 返回的类型，
+// 其它行为与 ReadConfig 一致，读取失败时返回 ErrReadInConfig，不存在时返回 fs.ErrNotExist
+func ReadProvider(config any, p Provider, opts ...Option) (*viper.Viper, error) {
+	return readProvider(config, p, nil, opts)
+}
+
+// readProvider 与 ReadProvider 一致，h 不为空时写入读取的内容
+func readProvider(config any, p Provider, h hash.Hash, opts []Option) (*viper.Viper, error) {
+	data, format, err := p.Read()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	o := newOptions(opts)
+	if o.format == "" {
+		o.format = format
+	}
+
+	fileType, err := o.configType("", data)
+	if err != nil {
+		return nil, err
+	}
+
+	inc := &includer{hash: h}
+	if fp, ok := p.(*FileProvider); ok {
+		inc.name = fp.filename
+	}
+
+	return read(config, data, fileType, inc, o)
+}
+
+// WatchProvider 与 Watch 一致，读取 p 中的配置并在 p 实现了 Watchable 时监听变化，
+// 没有实现 Watchable 时只读取一次
+func WatchProvider(ctx context.Context, p Provider, newTarget func() any, onChange func(old, new any), opts ...Option) (*Watcher, error) {
+	return watch(ctx, p, func(target any, h hash.Hash) error {
+		_, err := readProvider(target, p, h, opts)
+		return err
+	}, newTarget, onChange, opts)
+}
+
+// FileProvider 读取本地文件，类型根据文件后缀判断
+type FileProvider struct {
+	filename string
+}
+
+// NewFileProvider 创建读取 filename 的 Provider
+func NewFileProvider(filename string) *FileProvider {
+	return &FileProvider{filename: filename}
+}
+
+func (p *FileProvider) Read() ([]byte, string, error) {
+	data, err := os.ReadFile(p.filename)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, extType(p.filename), nil
+}
+
+// Watch 监听文件所在目录而不是文件本身，这样编辑器的 rename 保存以及 k8s ConfigMap 的软链接切换都可以感知到
+func (p *FileProvider) Watch(ctx context.Context, notify func(error)) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := fw.Add(filepath.Dir(p.filename)); err != nil {
+		fw.Close()
+		return err
+	}
+
+	go func() {
+		defer fw.Close()
+
+		file := filepath.Clean(p.filename)
+		realPath, _ := filepath.EvalSymlinks(file)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+
+				current, _ := filepath.EvalSymlinks(file)
+				if filepath.Clean(ev.Name) != file && current == realPath {
+					continue
+				}
+				realPath = current
+
+				notify(nil)
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+				notify(err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// MemoryProvider 内存中的配置内容，主要用于测试，Set 会通知所有的监听者
+type MemoryProvider struct {
+	mu      sync.Mutex
+	data    []byte
+	format  string
+	notifys []func(error)
+}
+
+// NewMemoryProvider 创建内容为 data 的 Provider，format 为空时根据内容判断类型
+func NewMemoryProvider(data []byte, format string) *MemoryProvider {
+	return &MemoryProvider{data: data, format: format}
+}
+
+func (p *MemoryProvider) Read() ([]byte, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]byte(nil), p.data...), p.format, nil
+}
+
+// Set 替换配置内容并通知监听者
+func (p *MemoryProvider) Set(data []byte) {
+	p.mu.Lock()
+	p.data = append([]byte(nil), data...)
+	notifys := append([]func(error){}, p.notifys...)
+	p.mu.Unlock()
+
+	for _, notify := range notifys {
+		notify(nil)
+	}
+}
+
+func (p *MemoryProvider) Watch(ctx context.Context, notify func(error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := len(p.notifys)
+	p.notifys = append(p.notifys, notify)
+	context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.notifys[i] = func(error) {}
+	})
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "port.cfg"), "http_port = 10000\n")
+	filename := filepath.Join(dir, "server.cfg")
+	writeFile(t, filename, "[server]\nname = file\n!include port.cfg\n")
+
+	var c watchConfig
+	v, err := ReadProvider(&c, NewFileProvider(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "file" || c.Server.HttpPort != 10000 || v.GetString("server::name") != "file" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	if _, err := ReadProvider(&c, NewFileProvider(filepath.Join(dir, "missing.cfg"))); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v, want fs.ErrNotExist", err)
+	}
+
+	c = watchConfig{}
+	if _, err := ReadProvider(&c, NewMemoryProvider([]byte(`{"server": {"name": "memory"}}`), "")); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "memory" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	if _, err := ReadProvider(&c, NewMemoryProvider([]byte("server:\n  http_port: abc\n"), "yaml")); !errors.Is(err, ErrUnmarshal) {
+		t.Fatalf("err = %v, want ErrUnmarshal", err)
+	}
+}
+
+func TestWatchMemoryProvider(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewMemoryProvider([]byte("server:\n  name: first\n"), "yaml")
+	changed := make(chan any, 1)
+	w, err := WatchProvider(ctx, p,
+		func() any { return new(watchConfig) },
+		func(old, new any) { changed <- new },
+		WithDebounce(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Set([]byte("server:\n  name: second\n"))
+	select {
+	case c := <-changed:
+		if c.(*watchConfig).Server.Name != "second" || w.Load().(*watchConfig).Server.Name != "second" {
+			t.Fatalf("unexpected config %+v", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for change")
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		body     = `{"server": {"name": "first", "http_port": 10000}}`
+		etag     = `"v1"`
+		requests atomic.Int32
+		notMod   atomic.Int32
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			notMod.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	p, err := NewHTTPProvider(ts.URL+"/server", WithHeader("Authorization", "Bearer token"), WithPollInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan any, 1)
+	w, err := WatchProvider(ctx, p,
+		func() any { return new(watchConfig) },
+		func(old, new any) { changed <- new },
+		WithDebounce(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Load().(*watchConfig).Server.Name; got != "first" {
+		t.Fatalf("name = %q, want first", got)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if notMod.Load() == 0 {
+		t.Fatal("expected conditional requests with If-None-Match")
+	}
+
+	mu.Lock()
+	body, etag = `{"server": {"name": "second", "http_port": 10001}}`, `"v2"`
+	mu.Unlock()
+
+	select {
+	case c := <-changed:
+		if c.(*watchConfig).Server.HttpPort != 10001 {
+			t.Fatalf("unexpected config %+v", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for change")
+	}
+
+	unauthorized, _ := NewHTTPProvider(ts.URL)
+	var c watchConfig
+	if _, err := ReadProvider(&c, unauthorized); !errors.Is(err, ErrReadInConfig) {
+		t.Fatalf("err = %v, want ErrReadInConfig", err)
+	}
+}
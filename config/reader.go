@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ReadConfigFrom 从 r 中读取配置内容并解析到 config 上，适用于通过网络获取的配置，
+// format 为配置的类型，规则与文件后缀一致(例如 cfg 同样会转为 ini 并使用 :: 作为分割符)，
+// 为空时根据内容判断类型，其它行为与 ReadConfig 一致
+func ReadConfigFrom(config any, r io.Reader, format string, opts ...Option) (*viper.Viper, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	return ReadConfigBytes(config, data, format, opts...)
+}
+
+// ReadConfigBytes 解析 data 中的配置内容到 config 上，format 的规则与 ReadConfigFrom 一致，
+// ini/cfg 中 include 指令的相对路径基于当前工作目录
+func ReadConfigBytes(config any, data []byte, format string, opts ...Option) (*viper.Viper, error) {
+	o := newOptions(opts)
+	if format != "" {
+		o.format = strings.ToLower(format)
+	}
+
+	fileType, err := o.configType("", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return read(config, data, fileType, &includer{}, o)
+}
+
+// ReadConfigFS 读取 fsys 中名称为 name 的配置文件并解析到 config 上，例如通过 embed.FS 嵌入到程序中的默认配置，
+// 文件类型的判断规则与 ReadConfig 一致，ini/cfg 中的 include 指令同样从 fsys 中读取
+func ReadConfigFS(config any, fsys fs.FS, name string, opts ...Option) (*viper.Viper, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, errors.Join(ErrReadInConfig, err)
+	}
+
+	o := newOptions(opts)
+
+	fileType, err := o.configType(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return read(config, data, fileType, &includer{fsys: fsys, name: name}, o)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type readerConfig struct {
+	Server struct {
+		Name      string `mapstructure:"name"`
+		HttpPort  int    `mapstructure:"http_port"`
+		TraceAddr string `mapstructure:"trace.addr"`
+	} `mapstructure:"server"`
+}
+
+func TestReadConfigFrom(t *testing.T) {
+	content := "[server]\nname = test\nhttp_port = 10000\ntrace.addr = http://0.0.0.0:7820\n"
+
+	for _, format := range []string{"cfg", "CFG", ""} {
+		var c readerConfig
+		v, err := ReadConfigFrom(&c, strings.NewReader(content), format)
+		if err != nil {
+			t.Fatalf("format %q: %v", format, err)
+		}
+		if c.Server.TraceAddr != "http://0.0.0.0:7820" || v.GetInt("server::http_port") != 10000 {
+			t.Fatalf("format %q: unexpected config %+v", format, c)
+		}
+	}
+
+	var c readerConfig
+	if _, err := ReadConfigBytes(&c, []byte(`{"server": {"name": "json"}}`), "json"); err != nil || c.Server.Name != "json" {
+		t.Fatalf("err = %v, config %+v", err, c)
+	}
+
+	if _, err := ReadConfigFrom(&c, strings.NewReader(content), "xml"); !errors.Is(err, ErrFileTypeNotAllow) {
+		t.Fatalf("err = %v, want ErrFileTypeNotAllow", err)
+	}
+}
+
+func TestReadConfigFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/server.cfg": {Data: []byte("[server]\nname = embed\ntrace.addr = http://0.0.0.0:7820\n")},
+		"conf/.atlantis":  {Data: []byte("[server]\nname = atlantis\n")},
+		"conf/app.yaml":   {Data: []byte("server:\n  name: yaml\n")},
+	}
+
+	for name, want := range map[string]string{"conf/server.cfg": "embed", "conf/.atlantis": "atlantis", "conf/app.yaml": "yaml"} {
+		var c readerConfig
+		if _, err := ReadConfigFS(&c, fsys, name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c.Server.Name != want {
+			t.Fatalf("%s: name = %q, want %q", name, c.Server.Name, want)
+		}
+	}
+
+	var c readerConfig
+	if _, err := ReadConfigFS(&c, fsys, "conf/missing.cfg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaDraft 生成的 JSON Schema 使用的版本
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// descTagName 字段说明使用的 tag 名称
+const descTagName = "desc"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	daysType     = reflect.TypeOf(Days(0))
+)
+
+// Schema 根据配置结构体生成 JSON Schema (draft 2020-12)，可以用于编辑器自动补全以及在 CI 中检查配置文件。
+// 属性名称依次使用 mapstructure, json, yaml tag，都没有时使用字段名称；
+// default tag 生成 default，validate tag 中的 required, min, max, len, oneof 生成对应的约束，desc tag 生成 description
+func Schema(target any) ([]byte, error) {
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return nil, errors.New("schema target is nil")
+	}
+
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("schema target must be a struct, got " + t.Kind().String())
+	}
+
+	root := newOrderedMap()
+	root.set("$schema", schemaDraft)
+
+	s := typeSchema(t, map[reflect.Type]bool{})
+	for _, k := range s.keys {
+		root.set(k, s.values[k])
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// orderedMap 按照插入顺序序列化的 JSON 对象，保持属性与结构体字段的顺序一致
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]any)}
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) *orderedMap {
+	t = indirectType(t)
+	s := newOrderedMap()
+
+	switch {
+	case t == durationType, t == byteSizeType, t == daysType:
+		s.set("type", []string{"string", "integer"})
+		return s
+	case t == timeType:
+		s.set("type", "string")
+		s.set("format", "date-time")
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s.set("type", "boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.set("type", "integer")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s.set("type", "integer")
+		s.set("minimum", 0)
+	case reflect.Float32, reflect.Float64:
+		s.set("type", "number")
+	case reflect.String:
+		s.set("type", "string")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.set("type", "string")
+			break
+		}
+		s.set("type", "array")
+		s.set("items", typeSchema(t.Elem(), visiting))
+	case reflect.Map:
+		s.set("type", "object")
+		s.set("additionalProperties", typeSchema(t.Elem(), visiting))
+	case reflect.Struct:
+		if visiting[t] {
+			s.set("type", "object")
+			break
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		props := newOrderedMap()
+		var required []string
+		structSchema(t, props, &required, visiting)
+
+		s.set("type", "object")
+		s.set("properties", props)
+		if len(required) > 0 {
+			s.set("required", required)
+		}
+	}
+
+	return s
+}
+
+func structSchema(t reflect.Type, props *orderedMap, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		if squash {
+			if ft := indirectType(f.Type); ft.Kind() == reflect.Struct {
+				structSchema(ft, props, required, visiting)
+			}
+			continue
+		}
+
+		s := typeSchema(f.Type, visiting)
+		if desc := f.Tag.Get(descTagName); desc != "" {
+			s.set("description", desc)
+		}
+		if def, ok := f.Tag.Lookup(defaultTagName); ok {
+			s.set("default", schemaValue(f.Type, def))
+		}
+		if rules := f.Tag.Get(validateTagName); rules != "" {
+			if schemaRules(s, f.Type, rules) {
+				*required = append(*required, name)
+			}
+		}
+
+		props.set(name, s)
+	}
+}
+
+// schemaRules 将 validate 规则转换为 JSON Schema 的约束，返回字段是否为必填
+func schemaRules(s *orderedMap, t reflect.Type, rules string) (required bool) {
+	t = indirectType(t)
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			if t == durationType || t == byteSizeType || t == daysType {
+				continue
+			}
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			for _, key := range limitKeys(t, name) {
+				s.set(key, n)
+			}
+		case "oneof":
+			values := strings.Fields(param)
+			enum := make([]any, 0, len(values))
+			for _, v := range values {
+				enum = append(enum, schemaValue(t, v))
+			}
+			s.set("enum", enum)
+		}
+	}
+
+	return required
+}
+
+// limitKeys 获取 min/max/len 规则对应的 JSON Schema 关键字
+func limitKeys(t reflect.Type, rule string) []string {
+	var lower, upper string
+	switch t.Kind() {
+	case reflect.String:
+		lower, upper = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		lower, upper = "minItems", "maxItems"
+	case reflect.Map:
+		lower, upper = "minProperties", "maxProperties"
+	default:
+		if rule == "len" {
+			return nil
+		}
+		lower, upper = "minimum", "maximum"
+	}
+
+	switch rule {
+	case "min":
+		return []string{lower}
+	case "max":
+		return []string{upper}
+	default:
+		return []string{lower, upper}
+	}
+}
+
+// schemaValue 将 tag 中的字符串按照字段类型转换为 JSON 中的值，转换失败时保持字符串
+func schemaValue(t reflect.Type, s string) any {
+	t = indirectType(t)
+	if t == durationType || t == byteSizeType || t == daysType {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return s
+		}
+		items := make([]any, 0)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, schemaValue(t.Elem(), item))
+			}
+		}
+		return items
+	}
+
+	return s
+}
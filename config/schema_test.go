@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchema(t *testing.T) {
+	type server struct {
+		Name    string        `mapstructure:"name" validate:"required" desc:"service name"`
+		HttPort int           `mapstructure:"http_port" default:"10000" validate:"min=1,max=65535"`
+		Format  string        `json:"format" validate:"oneof=json console"`
+		MaxAge  time.Duration `yaml:"maxAge" default:"7d"`
+		Ignored string        `mapstructure:"-"`
+	}
+	type config struct {
+		Server server `mapstructure:"server"`
+		Note   struct {
+			UseLibs []string `mapstructure:"UseLibs" default:"viper,ini" validate:"max=5"`
+		}
+		Labels map[string]string `mapstructure:"labels"`
+	}
+
+	data, err := Schema(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"$schema": schemaDraft,
+		"type":    "object",
+		"properties": map[string]any{
+			"server": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":      map[string]any{"type": "string", "description": "service name"},
+					"http_port": map[string]any{"type": "integer", "default": 10000.0, "minimum": 1.0, "maximum": 65535.0},
+					"Format":    map[string]any{"type": "string", "enum": []any{"json", "console"}},
+					"MaxAge":    map[string]any{"type": []any{"string", "integer"}, "default": "7d"},
+				},
+				"required": []any{"name"},
+			},
+			"Note": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"UseLibs": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "default": []any{"viper", "ini"}, "maxItems": 5.0},
+				},
+			},
+			"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("schema mismatch\n got: %s", data)
+	}
+
+	if _, err := Schema(1); err == nil {
+		t.Fatal("expected error for non struct target")
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ErrSectionNotFound DecodeSection 指定的 section 不存在
+var ErrSectionNotFound = errors.New("config section not found")
+
+// sectionDelimiter DecodeSection 解析单个 section 时使用的分割符，section 内的 key 包含 . 时不会被拆分
+const sectionDelimiter = "::"
+
+// Sections 返回 v 中名称以 prefix 开头的 section，按名称排序，prefix 不区分大小写，例如 db. 返回 db.shard1, db.shard2。
+// section 名称中包含 . 时需要使用 cfg 格式或者 WithDelimiter("::")，默认的 . 分割符会把 [db.shard1] 拆分为两层
+func Sections(v *viper.Viper, prefix string) []string {
+	prefix = strings.ToLower(prefix)
+
+	var names []string
+	for name, value := range v.AllSettings() {
+		if _, ok := value.(map[string]any); !ok {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DecodeSection 将 v 中名称为 name 的 section 解析为 T，与 ReadConfig 一致会补充 default tag 的默认值并按照 validate tag 校验
+func DecodeSection[T any](v *viper.Viper, name string) (T, error) {
+	var t T
+
+	m, ok := v.Get(name).(map[string]any)
+	if !ok {
+		return t, fmt.Errorf("%w: %s", ErrSectionNotFound, name)
+	}
+
+	sv := viper.NewWithOptions(viper.KeyDelimiter(sectionDelimiter))
+	if err := sv.MergeConfigMap(m); err != nil {
+		return t, errors.Join(ErrUnmarshal, err)
+	}
+
+	applyDefaults(sv, &t, sectionDelimiter, foldMatch)
+
+	if err := unmarshal(sv, &t); err != nil {
+		return t, errors.Join(ErrUnmarshal, err)
+	}
+
+	if err := validate(&t, sectionDelimiter); err != nil {
+		return t, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type shardConfig struct {
+	Host    string        `mapstructure:"host" validate:"required"`
+	Port    int           `mapstructure:"port" default:"3306"`
+	Timeout time.Duration `mapstructure:"timeout" default:"1d"`
+}
+
+func TestSections(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "db.cfg")
+	writeFile(t, filename, `app_name = demo
+
+[db.shard2]
+host = 10.0.0.2
+port = 3307
+
+[db.shard1]
+host = 10.0.0.1
+timeout = 5s
+
+[db.shard3]
+port = 3308
+
+[cache]
+host = 10.0.0.9
+`)
+
+	var c struct{}
+	v, err := ReadConfig(&c, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := Sections(v, "DB.")
+	if want := []string{"db.shard1", "db.shard2", "db.shard3"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Sections = %v, want %v", names, want)
+	}
+	if all := Sections(v, ""); len(all) != 5 {
+		t.Fatalf("Sections = %v, want 5 sections", all)
+	}
+
+	s1, err := DecodeSection[shardConfig](v, "db.shard1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1 != (shardConfig{Host: "10.0.0.1", Port: 3306, Timeout: 5 * time.Second}) {
+		t.Fatalf("unexpected shard1 %+v", s1)
+	}
+
+	s2, err := DecodeSection[*shardConfig](v, "db.shard2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s2 != (shardConfig{Host: "10.0.0.2", Port: 3307, Timeout: 24 * time.Hour}) {
+		t.Fatalf("unexpected shard2 %+v", *s2)
+	}
+
+	if _, err := DecodeSection[shardConfig](v, "db.shard3"); !errors.Is(err, ErrValidate) {
+		t.Fatalf("expected ErrValidate, got %v", err)
+	}
+	if _, err := DecodeSection[shardConfig](v, "db.shard4"); !errors.Is(err, ErrSectionNotFound) {
+		t.Fatalf("expected ErrSectionNotFound, got %v", err)
+	}
+}
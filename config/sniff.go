@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var (
+	sniffSection = regexp.MustCompile(`^\[[^\[\]"',{}]+\]$`)
+	sniffTable   = regexp.MustCompile(`^\[\[[^\[\]"',{}]+\]\]$`)
+	sniffYAMLKey = regexp.MustCompile(`^(- |[\w.\-"']+:(\s|$))`)
+	sniffDotenv  = regexp.MustCompile(`^(export\s+)?[A-Za-z_][A-Za-z0-9_]*=`)
+	sniffAssign  = regexp.MustCompile(`^[\w.\-"']+\s*=\s*(.*)$`)
+	sniffTOML    = regexp.MustCompile(`^("([^"\\]|\\.)*"|'[^']*'|[+-]?\d[\d_]*(\.\d+)?([eE][+-]?\d+)?|true|false|\[.*\]|\{.*\}|\d{4}-\d{2}-\d{2}.*)$`)
+	// sniffTOMLOnly 只有 toml 才会出现的值: 带引号的字符串、数组、inline table 以及日期，数字和布尔值在 cfg 中也很常见
+	sniffTOMLOnly = regexp.MustCompile(`^("([^"\\]|\\.)*"|'[^']*'|\[.*\]|\{.*\}|\d{4}-\d{2}-\d{2}.*)$`)
+)
+
+// sniffType 根据文件内容判断配置文件的类型，无法判断时返回空字符串
+//
+//	以 { 或者 [ 开头且不是 [section] 的为 json
+//	包含 [[table]] 的为 toml
+//	所有的值都是 toml 字面量且至少有一个只有 toml 才会出现的值(字符串、数组、inline table、日期)的为 toml
+//	包含 [section] 的其它情况为 cfg
+//	包含 key: value 行的为 yaml
+//	所有的行都是 KEY=VALUE 的为 dotenv
+//	所有的行都是 key = value 的为 cfg
+func sniffType(data []byte) string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	first := lines[0]
+	if first[0] == '{' || (first[0] == '[' && !sniffSection.MatchString(first) && !sniffTable.MatchString(first)) {
+		return "json"
+	}
+
+	var sections, tables, yamlKeys, dotenv, assigns, tomlValues, tomlOnly int
+	for _, line := range lines {
+		switch {
+		case sniffSection.MatchString(line):
+			sections++
+			continue
+		case sniffTable.MatchString(line):
+			tables++
+			continue
+		case line == "---" || sniffYAMLKey.MatchString(line):
+			yamlKeys++
+		}
+
+		if sniffDotenv.MatchString(line) {
+			dotenv++
+		}
+		if m := sniffAssign.FindStringSubmatch(line); m != nil {
+			assigns++
+			value := stripComment(m[1])
+			if sniffTOML.MatchString(value) {
+				tomlValues++
+			}
+			if sniffTOMLOnly.MatchString(value) {
+				tomlOnly++
+			}
+		}
+	}
+
+	entries := len(lines) - sections - tables
+	isTOML := tomlValues == assigns && tomlOnly > 0
+	switch {
+	case sections+tables > 0 && assigns == entries:
+		if tables > 0 || isTOML {
+			return "toml"
+		}
+		return "cfg"
+	case yamlKeys > 0 && assigns == 0 && sections+tables == 0:
+		return "yaml"
+	case dotenv == entries:
+		return "dotenv"
+	case assigns == entries && isTOML:
+		return "toml"
+	case assigns == entries:
+		return "cfg"
+	}
+
+	return ""
+}
+
+// stripComment 去除行尾的 # 注释，忽略引号中的 #
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return strings.TrimSpace(s[:i])
+		}
+	}
+
+	return s
+}
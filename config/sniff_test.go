@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffType(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"json object", `{"server": {"name": "test"}}`, "json"},
+		{"json array", "[\n  {\"name\": \"test\"}\n]", "json"},
+		{"cfg", "; comment\n[server]\nname = test\nhttp_port = 10000 # port\ntrace.addr = http://0.0.0.0:7820\n", "cfg"},
+		{"toml", "title = \"test\"\n\n[server]\nname = \"test\"\nhttp_port = 10000\n", "toml"},
+		{"cfg numbers only", "[server]\nhttp_port = 10000\ntrace.addr = 2\ndebug = true\n", "cfg"},
+		{"toml table array", "[[shards]]\nhost = \"a\"\n", "toml"},
+		{"toml array", "[server]\nports = [1, 2]\n", "toml"},
+		{"yaml", "---\nserver:\n  name: test\n  libs:\n    - viper\n", "yaml"},
+		{"dotenv", "# env\nexport APP_NAME=test\nAPP_PORT=10000\n", "dotenv"},
+		{"empty", "\n# only comment\n", ""},
+		{"unknown", "just some text\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := sniffType([]byte(tt.content)); got != tt.want {
+			t.Errorf("%s: sniffType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadConfigWithoutExtension(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config")
+	writeFile(t, filename, "server:\n  name: test\n  http_port: 10000\n")
+
+	var c struct {
+		Server struct {
+			Name     string `mapstructure:"name"`
+			HttpPort int    `mapstructure:"http_port"`
+		} `mapstructure:"server"`
+	}
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Name != "test" || c.Server.HttpPort != 10000 {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	// 只有数字的 [section] 文件按照 cfg 解析，trace.addr 不会被拆分为两层
+	numbers := filepath.Join(dir, "numbers")
+	writeFile(t, numbers, "[server]\nhttp_port = 10000\ntrace.addr = 2\n")
+	var n struct {
+		Server struct {
+			HttpPort  int `mapstructure:"http_port"`
+			TraceAddr int `mapstructure:"trace.addr"`
+		} `mapstructure:"server"`
+	}
+	if _, err := ReadConfig(&n, numbers); err != nil {
+		t.Fatal(err)
+	}
+	if n.Server.HttpPort != 10000 || n.Server.TraceAddr != 2 {
+		t.Fatalf("unexpected config %+v", n)
+	}
+
+	unknown := filepath.Join(dir, "notes")
+	writeFile(t, unknown, "just some text\n")
+	if _, err := ReadConfig(&c, unknown); !errors.Is(err, ErrFileTypeNotAllow) {
+		t.Fatalf("err = %v, want ErrFileTypeNotAllow", err)
+	}
+}
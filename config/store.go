@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/magiconair/properties"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/subosito/gotenv"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// store 解析过程中保存配置的 key 和值，默认使用 viper(key 不区分大小写)，
+// WithCaseSensitive 时使用 caseStore 保留 key 原始的大小写
+type store interface {
+	AllKeys() []string
+	AllSettings() map[string]any
+	Get(key string) any
+	Set(key string, value any)
+	SetDefault(key string, value any)
+	InConfig(key string) bool
+}
+
+// keyMatch 判断配置中的 key 与结构体字段的名称是否匹配
+type keyMatch func(key, name string) bool
+
+// foldMatch 与 viper 以及 mapstructure 的默认行为一致不区分大小写
+func foldMatch(key, name string) bool {
+	return strings.EqualFold(key, name)
+}
+
+// exactMatch 区分大小写
+func exactMatch(key, name string) bool {
+	return key == name
+}
+
+// caseStore 区分大小写的 store，key 使用 delim 分割层级
+type caseStore struct {
+	delim string
+	data  map[string]any
+}
+
+func newCaseStore(data map[string]any, delim string) *caseStore {
+	return &caseStore{delim: delim, data: data}
+}
+
+func (s *caseStore) AllKeys() []string {
+	flat := make(map[string]any)
+	flattenMap(s.data, "", s.delim, flat)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (s *caseStore) AllSettings() map[string]any {
+	return s.data
+}
+
+func (s *caseStore) Get(key string) any {
+	var value any = s.data
+	for _, k := range strings.Split(key, s.delim) {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if value, ok = m[k]; !ok {
+			return nil
+		}
+	}
+
+	return value
+}
+
+func (s *caseStore) Set(key string, value any) {
+	path := strings.Split(key, s.delim)
+	m := s.data
+	for _, k := range path[:len(path)-1] {
+		sub, ok := m[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[k] = sub
+		}
+		m = sub
+	}
+	m[path[len(path)-1]] = value
+}
+
+func (s *caseStore) SetDefault(key string, value any) {
+	if !s.InConfig(key) {
+		s.Set(key, value)
+	}
+}
+
+func (s *caseStore) InConfig(key string) bool {
+	return s.Get(key) != nil
+}
+
+// resolve 将不区分大小写的 key 转换为配置中已经存在的 key，例如环境变量转换得到的 server::http_port
+// 对应配置中的 Server::HTTP_PORT，不存在的层级保持原样
+func (s *caseStore) resolve(key string) string {
+	path := strings.Split(key, s.delim)
+	m := s.data
+	for i, k := range path {
+		name, value, ok := lookupKey(m, k, foldMatch)
+		if !ok {
+			break
+		}
+		path[i] = name
+		if m, ok = value.(map[string]any); !ok {
+			break
+		}
+	}
+
+	return strings.Join(path, s.delim)
+}
+
+// foldKeys 将 m 中的 key 转换为小写用于创建 viper 实例，只有大小写不同的 key 按照字节顺序排序后保留第一个，
+// 例如 Port 和 port 保留 Port 的值，两边都是 map 时递归合并
+func foldKeys(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	foldInto(out, m)
+
+	return out
+}
+
+func foldInto(dst, src map[string]any) {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		lower := strings.ToLower(k)
+		existing, exists := dst[lower]
+		if sub, ok := src[k].(map[string]any); ok {
+			d, isMap := existing.(map[string]any)
+			if !isMap {
+				if exists {
+					continue
+				}
+				d = make(map[string]any, len(sub))
+				dst[lower] = d
+			}
+			foldInto(d, sub)
+			continue
+		}
+		if !exists {
+			dst[lower] = src[k]
+		}
+	}
+}
+
+// decodeCase 按照文件类型解析配置内容并保留 key 原始的大小写，各个格式的处理与 viper 保持一致
+func decodeCase(data []byte, fileType, delim string) (map[string]any, error) {
+	m := make(map[string]any)
+
+	switch fileType {
+	case "json":
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case "hcl", "tfvars":
+		if err := hcl.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case "properties", "props", "prop":
+		p, err := properties.Load(data, properties.UTF8)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range p.Keys() {
+			value, _ := p.Get(key)
+			path := strings.Split(key, delim)
+			deepMap(m, path[:len(path)-1])[path[len(path)-1]] = value
+		}
+	case "dotenv", "env":
+		env, err := gotenv.StrictParse(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range env {
+			m[key] = value
+		}
+	case "ini", "cfg":
+		f := ini.Empty()
+		if err := f.Append(data); err != nil {
+			return nil, err
+		}
+		for _, section := range f.Sections() {
+			name := section.Name()
+			if name == ini.DefaultSection {
+				// 与 viper 一致，第一个 section 之前的 key 属于 default
+				name = "default"
+			}
+			for _, key := range section.Keys() {
+				deepMap(m, strings.Split(name, delim))[key.Name()] = key.String()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrFileTypeNotAllow, fileType)
+	}
+
+	return normalizeMap(m), nil
+}
+
+// deepMap 获取 path 对应的 map，不存在或者不是 map 时创建
+func deepMap(m map[string]any, path []string) map[string]any {
+	for _, k := range path {
+		sub, ok := m[k].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[k] = sub
+		}
+		m = sub
+	}
+
+	return m
+}
+
+// normalizeMap 将 yaml 等解析得到的 map[any]any 转换为 map[string]any
+func normalizeMap(m map[string]any) map[string]any {
+	for k, v := range m {
+		m[k] = normalizeValue(v)
+	}
+
+	return m
+}
+
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return normalizeMap(v)
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalizeValue(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeValue(item)
+		}
+		return v
+	}
+
+	return value
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// checkStrict 检查配置文件中的 key 是否都能映射到 config 结构体上，
+// unset 为 true 时同时检查结构体中没有在配置文件中出现的字段(声明了 default tag 的字段除外)，
+// 返回的错误中 key 使用配置文件自己的分割符
+func checkStrict(settings map[string]any, config any, delim string, unset bool, match keyMatch) error {
+	t := reflect.TypeOf(config)
+	if t == nil {
+		return nil
+	}
+	t = indirectType(t)
+
+	var unknown, missing []string
+	unknownKeys(settings, t, "", delim, match, &unknown)
+	if unset {
+		missingKeys(settings, t, "", delim, match, &missing)
+	}
+
+	if len(unknown) == 0 && len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	sort.Strings(missing)
+
+	errs := make([]error, 0, len(unknown)+len(missing))
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("unknown key %q", key))
+	}
+	for _, key := range missing {
+		errs = append(errs, fmt.Errorf("missing key %q", key))
+	}
+
+	return errors.Join(ErrStrict, errors.Join(errs...))
+}
+
+func unknownKeys(m map[string]any, t reflect.Type, prefix, delim string, match keyMatch, out *[]string) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for k, value := range m {
+		path := joinKey(prefix, k, delim)
+		f, ok := findField(t, k, match)
+		if !ok {
+			*out = append(*out, path)
+			continue
+		}
+
+		ft := indirectType(f.Type)
+		switch ft.Kind() {
+		case reflect.Struct:
+			if sub, ok := value.(map[string]any); ok {
+				unknownKeys(sub, ft, path, delim, match, out)
+			}
+		case reflect.Slice, reflect.Array:
+			elem := indirectType(ft.Elem())
+			items, _ := value.([]any)
+			for i, item := range items {
+				if sub, ok := item.(map[string]any); ok {
+					unknownKeys(sub, elem, fmt.Sprintf("%s[%d]", path, i), delim, match, out)
+				}
+			}
+		case reflect.Map:
+			elem := indirectType(ft.Elem())
+			items, _ := value.(map[string]any)
+			for name, item := range items {
+				if sub, ok := item.(map[string]any); ok {
+					unknownKeys(sub, elem, joinKey(path, name, delim), delim, match, out)
+				}
+			}
+		}
+	}
+}
+
+func missingKeys(m map[string]any, t reflect.Type, prefix, delim string, match keyMatch, out *[]string) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		ft := indirectType(f.Type)
+		if squash {
+			missingKeys(m, ft, prefix, delim, match, out)
+			continue
+		}
+
+		path := joinKey(prefix, key, delim)
+		_, value, ok := lookupKey(m, key, match)
+		if _, hasDefault := f.Tag.Lookup(defaultTagName); hasDefault {
+			continue
+		}
+
+		if ft.Kind() == reflect.Struct && hasExportedField(ft) {
+			sub, _ := value.(map[string]any)
+			missingKeys(sub, ft, path, delim, match, out)
+			continue
+		}
+
+		if !ok {
+			*out = append(*out, path)
+		}
+	}
+}
+
+// findField 在结构体中查找 key 对应的字段，match 判断名称是否匹配，会查找 squash 展开的内嵌结构体
+func findField(t reflect.Type, key string, match keyMatch) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash, skip := fieldKey(f)
+		if skip {
+			continue
+		}
+
+		if squash {
+			if ft := indirectType(f.Type); ft.Kind() == reflect.Struct {
+				if sf, ok := findField(ft, key, match); ok {
+					return sf, true
+				}
+			}
+			continue
+		}
+
+		if match(key, name) {
+			return f, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func hasExportedField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type strictConfig struct {
+	Server struct {
+		Name      string `mapstructure:"name"`
+		HttpPort  int    `mapstructure:"http_port"`
+		TraceAddr string `mapstructure:"trace.addr"`
+		Debug     bool   `mapstructure:"debug" default:"false"`
+	} `mapstructure:"server"`
+	Note
+}
+
+type Note struct {
+	Content string `mapstructure:"Content"`
+}
+
+func TestReadConfigStrict(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[server]\nname = test\nhttp_prot = 10000\ntrace.addr = http://0.0.0.0:7820\n\n[Extra]\nkey = value\n")
+
+	var c strictConfig
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatalf("non strict mode: %v", err)
+	}
+
+	_, err := ReadConfig(&c, filename, WithStrict())
+	if !errors.Is(err, ErrStrict) {
+		t.Fatalf("err = %v, want ErrStrict", err)
+	}
+	want := "config keys mismatch\nunknown key \"extra\"\nunknown key \"server::http_prot\""
+	if err.Error() != want {
+		t.Fatalf("err = %q, want %q", err, want)
+	}
+
+	_, err = ReadConfig(&c, filename, WithStrictFields())
+	for _, key := range []string{`missing key "server::http_port"`, `missing key "Note::Content"`} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("err %q does not contain %s", err, key)
+		}
+	}
+	if strings.Contains(err.Error(), "server::debug") {
+		t.Errorf("field with default reported: %v", err)
+	}
+}
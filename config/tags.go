@@ -0,0 +1,53 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagName 解析配置结构体时使用的 tag 名称，viper 在后台使用 mapstructure 进行解析
+const tagName = "mapstructure"
+
+// fieldKey 获取结构体字段在配置文件中对应的 key，规则与 mapstructure 保持一致
+// squash 为 true 表示字段为内嵌展开的结构体，skip 为 true 表示该字段不参与解析
+func fieldKey(f reflect.StructField) (key string, squash, skip bool) {
+	if !f.IsExported() {
+		return "", false, true
+	}
+
+	tag := f.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+
+	if name == "" {
+		name = f.Name
+	}
+
+	return name, squash, false
+}
+
+// joinKey 使用分割符拼接 key
+func joinKey(prefix, key, delim string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + delim + key
+}
+
+// indirectType 获取指针指向的最终类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteSize 字节数，配置中可以使用 512MiB, 1.5GB, 100K 形式的字符串或者整数(单位为字节)，
+// KB/MB/GB/TB 按照 1000 进制计算，K/M/G/T 以及 KiB/MiB/GiB/TiB 按照 1024 进制计算
+type ByteSize int64
+
+const (
+	Byte ByteSize = 1
+	KiB           = Byte << 10
+	MiB           = KiB << 10
+	GiB           = MiB << 10
+	TiB           = GiB << 10
+)
+
+var byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   KiB,
+	"kib": KiB,
+	"kb":  1000,
+	"m":   MiB,
+	"mib": MiB,
+	"mb":  1000 * 1000,
+	"g":   GiB,
+	"gib": GiB,
+	"gb":  1000 * 1000 * 1000,
+	"t":   TiB,
+	"tib": TiB,
+	"tb":  1000 * 1000 * 1000 * 1000,
+}
+
+// ParseByteSize 解析字节数，例如 512MiB, 1.5GB, 100K, 1024
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, s[i:])
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	size := n * float64(unit)
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: overflow", s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// String 使用能整除的最大的 1024 进制单位输出，例如 512MiB
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{{"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// MarshalText 用于 Dump 输出可读的格式
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Days 天数，配置中可以使用整数(单位为天)或者 7d, 168h 形式的时间间隔，时间间隔必须是整天
+type Days int
+
+// ParseDays 解析天数，例如 7, 7d, 168h
+func ParseDays(s string) (Days, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return Days(n), nil
+	}
+
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid days %q", s)
+	}
+	if d%(24*time.Hour) != 0 {
+		return 0, fmt.Errorf("invalid days %q: not a whole number of days", s)
+	}
+
+	return Days(d / (24 * time.Hour)), nil
+}
+
+// Duration 转换为 time.Duration
+func (d Days) Duration() time.Duration {
+	return time.Duration(d) * 24 * time.Hour
+}
+
+func (d Days) String() string {
+	return strconv.Itoa(int(d)) + "d"
+}
+
+// MarshalText 用于 Dump 输出可读的格式
+func (d Days) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+	}{
+		{"1024", 1024},
+		{"512MiB", 512 * MiB},
+		{"512M", 512 * MiB},
+		{"1.5GB", 1500 * 1000 * 1000},
+		{"100 KiB", 100 * KiB},
+		{"2tb", 2 * 1000 * 1000 * 1000 * 1000},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "MiB", "10PB", "-1"} {
+		if _, err := ParseByteSize(in); err == nil {
+			t.Errorf("ParseByteSize(%q) should fail", in)
+		}
+	}
+
+	if s := (512 * MiB).String(); s != "512MiB" {
+		t.Errorf("String() = %q", s)
+	}
+	if s := ByteSize(1500).String(); s != "1500B" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	for in, want := range map[string]Days{"7": 7, "7d": 7, "168h": 7, "1d24h": 2} {
+		got, err := ParseDays(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDays(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+
+	if _, err := ParseDays("36h"); err == nil {
+		t.Error("ParseDays(36h) should fail")
+	}
+	if d := Days(7).Duration(); d != 7*24*time.Hour {
+		t.Errorf("Duration() = %s", d)
+	}
+}
+
+type loggerConfig struct {
+	Logger struct {
+		MaxSize    ByteSize      `mapstructure:"max_size"`
+		MaxAge     Days          `mapstructure:"max_age"`
+		RotateTime time.Duration `mapstructure:"rotate_time"`
+		BufferSize ByteSize      `mapstructure:"buffer_size" default:"64KiB"`
+	} `mapstructure:"logger"`
+}
+
+func TestReadConfigUnits(t *testing.T) {
+	files := map[string]string{
+		"logger.cfg":  "[logger]\nmax_size = 512MiB\nmax_age = 7d\nrotate_time = 90m\n",
+		"logger.yaml": "logger:\n  max_size: 512MiB\n  max_age: 7\n  rotate_time: 1h30m\n",
+		"logger.json": `{"logger": {"max_size": "512M", "max_age": "168h", "rotate_time": "90m"}}`,
+		"logger.toml": "[logger]\nmax_size = \"512MiB\"\nmax_age = \"7d\"\nrotate_time = \"90m\"\n",
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		writeFile(t, filename, content)
+
+		var c loggerConfig
+		if _, err := ReadConfig(&c, filename); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		l := c.Logger
+		if l.MaxSize != 512*MiB || l.MaxAge != 7 || l.RotateTime != 90*time.Minute || l.BufferSize != 64*KiB {
+			t.Fatalf("%s: unexpected config %+v", name, l)
+		}
+	}
+}
+
+func TestReadConfigUnitsError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "logger.cfg")
+	writeFile(t, filename, "[logger]\nmax_size = 512XB\n")
+
+	var c loggerConfig
+	_, err := ReadConfig(&c, filename)
+	if !errors.Is(err, ErrUnmarshal) || !strings.Contains(err.Error(), "max_size") {
+		t.Fatalf("err = %v, want error on max_size", err)
+	}
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// validateTagName 校验规则使用的 tag 名称，多个规则之间使用逗号分割
+// 例如 `validate:"required,min=1,max=65535"`, `validate:"oneof=json console"`
+const validateTagName = "validate"
+
+// Validate 按照结构体字段上的 validate tag 校验配置的值，支持的规则如下
+//
+//	required  值不能为零值，slice/map 不能为空
+//	omitempty 值为零值时跳过其它规则
+//	min=N     数值不能小于 N，string/slice/map 的长度不能小于 N
+//	max=N     数值不能大于 N，string/slice/map 的长度不能大于 N
+//	len=N     string/slice/map 的长度必须等于 N
+//	oneof=a b 值必须为空格分割的列表中的一个
+//
+// 其它规则会被忽略，因此可以与 go-playground/validator 等校验器共用同一个 validate tag，
+// 所有不满足的规则会合并为一个错误返回，每条错误都包含完整的 key 路径，key 之间使用 . 分割
+func Validate(config any) error {
+	return validate(config, ".")
+}
+
+func validate(config any, delim string) error {
+	var errs []error
+	validateValue(reflect.ValueOf(config), "", delim, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errors.Join(ErrValidate, errors.Join(errs...))
+}
+
+func validateValue(v reflect.Value, path, delim string, errs *[]error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key, squash, skip := fieldKey(f)
+			if skip {
+				continue
+			}
+
+			fieldPath := path
+			if !squash {
+				fieldPath = joinKey(path, key, delim)
+			}
+
+			if rules := f.Tag.Get(validateTagName); rules != "" {
+				if !validateField(v.Field(i), fieldPath, rules, errs) {
+					continue
+				}
+			}
+			validateValue(v.Field(i), fieldPath, delim, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), delim, errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), joinKey(path, fmt.Sprint(iter.Key().Interface()), delim), delim, errs)
+		}
+	}
+}
+
+// validateField 校验单个字段，返回 false 表示无需继续校验字段内部的值
+func validateField(v reflect.Value, path, rules string, errs *[]error) bool {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	zero := isEmptyValue(v)
+	valid := indirectValue(v).IsValid()
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "required":
+			if zero {
+				fail("is required")
+				return false
+			}
+		case "omitempty":
+			if zero {
+				return false
+			}
+		case "min", "max", "len":
+			if !valid {
+				continue
+			}
+			m, isLen, err := measure(v, param)
+			if err != nil {
+				fail("invalid rule %q: %v", rule, err)
+				continue
+			}
+			switch {
+			case name == "min" && m.value < m.limit:
+				fail("%s be at least %s, got %s", lengthOrValue(isLen), param, m.text)
+			case name == "max" && m.value > m.limit:
+				fail("%s be at most %s, got %s", lengthOrValue(isLen), param, m.text)
+			case name == "len" && m.value != m.limit:
+				fail("length must be %s, got %s", param, m.text)
+			}
+		case "oneof":
+			if !valid {
+				continue
+			}
+			got := fmt.Sprint(indirectValue(v))
+			if !oneOf(got, strings.Fields(param)) {
+				fail("must be one of [%s], got %q", param, got)
+			}
+		default:
+			// 其它规则(例如 go-playground/validator 的 email)交给业务自己的校验器，这里忽略
+		}
+	}
+
+	return true
+}
+
+type measurement struct {
+	value float64
+	limit float64
+	text  string
+}
+
+// measure 获取用于 min/max/len 比较的值，string/slice/map 使用长度，数值使用值本身
+func measure(v reflect.Value, param string) (m measurement, isLen bool, err error) {
+	v = indirectValue(v)
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		limit, err := time.ParseDuration(param)
+		if err != nil {
+			return m, false, err
+		}
+		d := time.Duration(v.Int())
+		return measurement{value: float64(d), limit: float64(limit), text: d.String()}, false, nil
+	}
+
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return m, false, err
+	}
+	m.limit = limit
+
+	switch v.Kind() {
+	case reflect.String:
+		n := utf8.RuneCountInString(v.String())
+		m.value, m.text, isLen = float64(n), strconv.Itoa(n), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		m.value, m.text, isLen = float64(v.Len()), strconv.Itoa(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		m.value, m.text = float64(v.Int()), strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		m.value, m.text = float64(v.Uint()), strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		m.value, m.text = v.Float(), strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return m, false, fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return m, isLen, nil
+}
+
+func lengthOrValue(isLen bool) string {
+	if isLen {
+		return "length must"
+	}
+
+	return "must"
+}
+
+func oneOf(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmptyValue 判断值是否为零值，slice/map 长度为 0 时也认为是零值
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// indirectValue 获取指针指向的值，nil 指针返回无效的 reflect.Value
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
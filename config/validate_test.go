@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type validateConfig struct {
+	Server struct {
+		Name     string `mapstructure:"name" validate:"required"`
+		HttpPort int    `mapstructure:"http_port" validate:"min=1,max=65535"`
+		Format   string `mapstructure:"format" validate:"oneof=json console"`
+	} `mapstructure:"server"`
+	Note struct {
+		UseLibs []string `mapstructure:"UseLibs" validate:"omitempty,max=2"`
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var c validateConfig
+	c.Server.HttpPort = 70000
+	c.Server.Format = "text"
+	c.Note.UseLibs = []string{"viper", "ini", "yaml"}
+
+	err := Validate(&c)
+	if !errors.Is(err, ErrValidate) {
+		t.Fatalf("err = %v, want ErrValidate", err)
+	}
+
+	for _, want := range []string{
+		"server.name: is required",
+		"server.http_port: must be at most 65535, got 70000",
+		`server.format: must be one of [json console], got "text"`,
+		"Note.UseLibs: length must be at most 2, got 3",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	c.Server.Name = "test"
+	c.Server.HttpPort = 10000
+	c.Server.Format = "json"
+	c.Note.UseLibs = nil
+	if err := Validate(&c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadConfigValidate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[server]\nname = test\nhttp_port = 0\nformat = json\n")
+
+	var c validateConfig
+	_, err := ReadConfig(&c, filename)
+	if !errors.Is(err, ErrValidate) || !strings.Contains(err.Error(), "server::http_port: must be at least 1, got 0") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestReadConfigUnknownValidateRules(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, "[server]\nemail = admin@example.com\nport = 8080\n")
+
+	var c struct {
+		Server struct {
+			Email string `mapstructure:"email" validate:"required,email"`
+			Port  int    `mapstructure:"port" validate:"gt=0,max=65535,dive"`
+		} `mapstructure:"server"`
+	}
+	if _, err := ReadConfig(&c, filename); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Email != "admin@example.com" || c.Server.Port != 8080 {
+		t.Fatalf("unexpected config %+v", c.Server)
+	}
+
+	// 已知的规则仍然生效
+	writeFile(t, filename, "[server]\nport = 70000\n")
+	if _, err := ReadConfig(&c, filename); !errors.Is(err, ErrValidate) || !strings.Contains(err.Error(), "server::port: must be at most 65535") {
+		t.Fatalf("err = %v, want ErrValidate", err)
+	}
+}
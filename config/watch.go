@@ -0,0 +1,239 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSnapshotNotFound Rollback 指定的版本不在保留的历史中
+var ErrSnapshotNotFound = errors.New("config snapshot not found")
+
+// ErrUnhealthy 重新加载后健康检查失败，已经回滚到上一个版本
+var ErrUnhealthy = errors.New("config health check failed")
+
+// Watcher 持有最近一次成功解析的配置结构体，以及最近 N 个成功解析的历史版本，详见 WithHistory
+type Watcher struct {
+	read      func(target any, h hash.Hash) error
+	newTarget func() any
+	onChange  func(old, new any)
+	o         *options
+
+	mu      sync.Mutex // 保护 history, version 以及 current 的修改
+	history []Snapshot
+	version int
+	current atomic.Pointer[Snapshot]
+}
+
+// Snapshot 一次成功解析的配置，Hash 为读取的原始内容(配置文件或 Provider 返回的内容，以及 include 的文件)的 sha256，
+// 环境变量、命令行参数以及 ${env:NAME}, ${file:/path} 引用的内容不参与计算
+type Snapshot struct {
+	Version  int
+	Hash     string
+	LoadedAt time.Time
+	Value    any
+}
+
+// Watch 读取配置文件并监听文件变化，文件变化后使用 newTarget 创建新的结构体重新解析，
+// 解析成功后原子替换当前值并回调 onChange；解析失败(ErrReadInConfig/ErrUnmarshal 等)时
+// 保留上一次成功的值，并通过 WithErrorHandler 设置的回调上报错误。
+// 文件类型的判断与 ReadConfig 保持一致, opts 同样会传给 ReadConfig, ctx 结束后停止监听
+func Watch(ctx context.Context, filename string, newTarget func() any, onChange func(old, new any), opts ...Option) (*Watcher, error) {
+	return watch(ctx, NewFileProvider(filename), func(target any, h hash.Hash) error {
+		_, err := readConfig(target, filename, h, opts)
+		return err
+	}, newTarget, onChange, opts)
+}
+
+// watch 使用 read 读取初始的配置，p 实现了 Watchable 时监听变化并重新读取
+func watch(ctx context.Context, p Provider, read func(target any, h hash.Hash) error, newTarget func() any, onChange func(old, new any), opts []Option) (*Watcher, error) {
+	w := &Watcher{
+		read:      read,
+		newTarget: newTarget,
+		onChange:  onChange,
+		o:         newOptions(opts),
+	}
+
+	if _, _, err := w.load(); err != nil {
+		return nil, err
+	}
+
+	wp, ok := p.(Watchable)
+	if !ok {
+		return w, nil
+	}
+
+	events := make(chan error)
+	notify := func(err error) {
+		select {
+		case events <- err:
+		case <-ctx.Done():
+		}
+	}
+	if err := wp.Watch(ctx, notify); err != nil {
+		return nil, err
+	}
+
+	go w.run(ctx, events)
+
+	return w, nil
+}
+
+// Load 返回当前生效的配置结构体
+func (w *Watcher) Load() any {
+	return w.current.Load().Value
+}
+
+// Current 返回当前生效的版本
+func (w *Watcher) Current() Snapshot {
+	return *w.current.Load()
+}
+
+// History 返回保留的历史版本，按照版本从旧到新排列，包含当前版本
+func (w *Watcher) History() []Snapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]Snapshot(nil), w.history...)
+}
+
+// Rollback 将 version 对应的历史版本设置为当前生效的版本并回调 onChange，
+// 版本不在保留的历史中时返回 ErrSnapshotNotFound
+func (w *Watcher) Rollback(version int) error {
+	w.mu.Lock()
+	i := w.indexOf(version)
+	if i < 0 {
+		w.mu.Unlock()
+		return fmt.Errorf("%w: version %d", ErrSnapshotNotFound, version)
+	}
+	target := w.history[i]
+	old := w.current.Swap(&target)
+	w.mu.Unlock()
+
+	if w.onChange != nil && old.Version != version {
+		w.onChange(old.Value, target.Value)
+	}
+
+	return nil
+}
+
+func (w *Watcher) indexOf(version int) int {
+	for i, s := range w.history {
+		if s.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// load 使用 newTarget 创建新的结构体并读取配置，成功后调用 push 记录新的版本
+func (w *Watcher) load() (old, current *Snapshot, err error) {
+	h := sha256.New()
+	target := w.newTarget()
+	if err := w.read(target, h); err != nil {
+		return nil, nil, err
+	}
+
+	old, current = w.push(target, hex.EncodeToString(h.Sum(nil)))
+	return old, current, nil
+}
+
+// push 记录新的版本并设置为当前生效的版本，健康检查通过后再调用 trim 丢弃多余的版本
+func (w *Watcher) push(value any, sum string) (old, current *Snapshot) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.version++
+	s := Snapshot{
+		Version:  w.version,
+		Hash:     sum,
+		LoadedAt: time.Now(),
+		Value:    value,
+	}
+
+	w.history = append(w.history, s)
+
+	return w.current.Swap(&s), &s
+}
+
+// trim 超过 WithHistory 设置的数量时丢弃最旧的版本
+func (w *Watcher) trim() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := len(w.history) - w.o.history; n > 0 {
+		w.history = append(w.history[:0:0], w.history[n:]...)
+	}
+}
+
+// drop 从历史中移除 version 对应的版本
+func (w *Watcher) drop(version int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if i := w.indexOf(version); i >= 0 {
+		w.history = append(w.history[:i:i], w.history[i+1:]...)
+	}
+}
+
+func (w *Watcher) run(ctx context.Context, events <-chan error) {
+	timer := time.NewTimer(w.o.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case err := <-events:
+			if err != nil {
+				w.o.errorHandler(err)
+				continue
+			}
+			timer.Reset(w.o.debounce)
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	old, current, err := w.load()
+	if err != nil {
+		w.o.errorHandler(err)
+		return
+	}
+	target := current.Value
+	if w.onChange != nil {
+		w.onChange(old.Value, target)
+	}
+
+	if w.o.healthCheck == nil {
+		w.trim()
+		return
+	}
+
+	if err := w.o.healthCheck(target); err != nil {
+		// 只有当前版本仍然是刚加载的版本时才回滚，避免覆盖健康检查期间手动 Rollback 的结果
+		w.mu.Lock()
+		reverted := w.current.CompareAndSwap(current, old)
+		w.mu.Unlock()
+		if reverted {
+			w.drop(current.Version)
+			if w.onChange != nil {
+				w.onChange(target, old.Value)
+			}
+		}
+		w.o.errorHandler(errors.Join(ErrUnhealthy, fmt.Errorf("version %d: %w", current.Version, err)))
+		return
+	}
+
+	w.trim()
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type watchConfig struct {
+	Server struct {
+		Name     string `mapstructure:"name"`
+		HttpPort int    `mapstructure:"http_port"`
+	} `mapstructure:"server"`
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "server.cfg")
+	writeFile(t, filename, "[server]\nname = first\nhttp_port = 10000\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan [2]any, 1)
+	errs := make(chan error, 1)
+	w, err := Watch(ctx, filename,
+		func() any { return new(watchConfig) },
+		func(old, new any) { changed <- [2]any{old, new} },
+		WithDebounce(20*time.Millisecond),
+		WithErrorHandler(func(err error) { errs <- err }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.Load().(*watchConfig).Server.Name; got != "first" {
+		t.Fatalf("name = %q, want first", got)
+	}
+
+	writeFile(t, filename, "[server]\nname = second\nhttp_port = 10001\n")
+	select {
+	case c := <-changed:
+		if c[0].(*watchConfig).Server.Name != "first" || c[1].(*watchConfig).Server.HttpPort != 10001 {
+			t.Fatalf("unexpected change %+v -> %+v", c[0], c[1])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for change")
+	}
+
+	writeFile(t, filename, "[server]\nhttp_port = not-a-number\n")
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for error")
+	}
+
+	if got := w.Load().(*watchConfig).Server.Name; got != "second" {
+		t.Fatalf("name = %q, want previous good value second", got)
+	}
+}
+
+func TestWatchHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewMemoryProvider([]byte("server:\n  name: v1\n"), "yaml")
+	changed := make(chan string, 10)
+	errs := make(chan error, 10)
+	w, err := WatchProvider(ctx, p,
+		func() any { return new(watchConfig) },
+		func(old, new any) { changed <- new.(*watchConfig).Server.Name },
+		WithDebounce(time.Millisecond),
+		WithHistory(2),
+		WithErrorHandler(func(err error) { errs <- err }),
+		WithHealthCheck(func(value any) error {
+			if value.(*watchConfig).Server.HttpPort == 0 {
+				return errors.New("port not set")
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait := func(want string) {
+		t.Helper()
+		select {
+		case got := <-changed:
+			if got != want {
+				t.Fatalf("changed to %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting for %q", want)
+		}
+	}
+
+	first := w.Current()
+	if first.Version != 1 || first.Hash == "" || first.LoadedAt.IsZero() {
+		t.Fatalf("unexpected snapshot %+v", first)
+	}
+
+	p.Set([]byte("server:\n  name: v2\n  http_port: 1\n"))
+	wait("v2")
+	p.Set([]byte("server:\n  name: v3\n  http_port: 2\n"))
+	wait("v3")
+
+	history := w.History()
+	if len(history) != 2 || history[0].Version != 2 || history[1].Version != 3 || history[0].Hash == history[1].Hash {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	// 健康检查失败时回滚到上一个版本
+	p.Set([]byte("server:\n  name: bad\n"))
+	wait("bad")
+	wait("v3")
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrUnhealthy) {
+			t.Fatalf("err = %v, want ErrUnhealthy", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for error")
+	}
+	if c := w.Current(); c.Version != 3 || len(w.History()) != 2 {
+		t.Fatalf("current = %+v, history = %+v", c, w.History())
+	}
+
+	if err := w.Rollback(2); err != nil {
+		t.Fatal(err)
+	}
+	wait("v2")
+	if got := w.Load().(*watchConfig).Server.Name; got != "v2" {
+		t.Fatalf("name = %q, want v2", got)
+	}
+
+	if err := w.Rollback(1); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("err = %v, want ErrSnapshotNotFound", err)
+	}
+}
+
+func TestWatchHash(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "server.cfg")
+	common := filepath.Join(dir, "common.cfg")
+	writeFile(t, filename, "include = common.cfg\n[server]\nname = app\n")
+
+	hashOf := func(newTarget func() any) string {
+		t.Helper()
+		w, err := Watch(ctx, filename, newTarget, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w.Current().Hash
+	}
+
+	// 只有结构体中没有的 key 不同时，Hash 也不同
+	writeFile(t, common, "[log]\nlevel = info\n")
+	h1 := hashOf(func() any { return new(watchConfig) })
+	writeFile(t, common, "[log]\nlevel = debug\n")
+	h2 := hashOf(func() any { return new(watchConfig) })
+	if h1 == "" || h1 == h2 {
+		t.Fatalf("hashes %q %q, want different non-empty values", h1, h2)
+	}
+	if h := hashOf(func() any { return new(watchConfig) }); h != h2 {
+		t.Fatalf("same content got different hashes %q %q", h, h2)
+	}
+
+	// 结构体无法序列化为 JSON 时 Hash 不为空
+	type withFunc struct {
+		Fn func()
+	}
+	if h := hashOf(func() any { return new(withFunc) }); h != h2 {
+		t.Fatalf("hash = %q, want %q", h, h2)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pemako/gopkg/config/internal/fileutil"
+)
+
+// Set 修改 ini/cfg 文件中 key 对应的值，key 使用文件类型对应的分割符，例如 cfg 文件中的 server::http_port，
+// 没有分割符的 key 属于第一个 section 之前的部分。只修改对应的一行，注释、顺序以及格式保持不变，
+// key 不存在时追加到对应 section 的最后，section 不存在时追加到文件末尾。
+// 通过临时文件加 rename 的方式原子写入，include 引用的文件中的 key 不会被修改
+func Set(filename, key string, value any) error {
+	filename, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	fileType, err := configType(filename, data)
+	if err != nil {
+		return err
+	}
+	if fileType != "ini" && fileType != "cfg" {
+		return fmt.Errorf("%w: Set only supports ini and cfg, got %s", ErrFileTypeNotAllow, fileType)
+	}
+
+	section, name := "", key
+	if i := strings.LastIndex(key, keyDelimiter(fileType)); i >= 0 {
+		section, name = key[:i], key[i+len(keyDelimiter(fileType)):]
+	}
+	if name == "" {
+		return fmt.Errorf("invalid key %q", key)
+	}
+
+	text := formatLeaf(dumpValue(reflect.ValueOf(value)))
+	if strings.ContainsAny(text, "\r\n") {
+		return fmt.Errorf("invalid value for key %q: contains newline", key)
+	}
+
+	return fileutil.WriteFile(filename, setValue(data, section, name, iniValue(text)))
+}
+
+// setValue 修改 data 中 section 下 name 对应的值，重复出现时修改最后一个，与解析时后出现的覆盖先出现的一致
+func setValue(data []byte, section, name, value string) []byte {
+	eol := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		eol = "\r\n"
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), eol), eol)
+
+	var (
+		current    string
+		found      = -1 // 最后一个匹配的 key 所在的行
+		lastInSect = -1 // 目标 section 中最后一个 key 所在的行
+		sectLine   = -1 // 目标 section 最后一次出现的行
+	)
+	for i, line := range lines {
+		if s, ok := iniSection(line); ok {
+			current = s
+			if strings.EqualFold(current, section) {
+				sectLine, lastInSect = i, i
+			}
+			continue
+		}
+		if !strings.EqualFold(current, section) && !(section == "" && strings.EqualFold(current, "default")) {
+			continue
+		}
+
+		if k, _, ok := iniKeyValue(line); ok {
+			lastInSect = i
+			if strings.EqualFold(k, name) {
+				found = i
+			}
+		}
+	}
+
+	switch {
+	case found >= 0:
+		lines[found] = replaceValue(lines[found], value)
+	case section == "":
+		// 插入到第一个 section 之前的最后一个 key 后面，没有 key 时插入到文件开头
+		lines = insertLine(lines, lastInSect+1, name+" = "+value)
+	case sectLine >= 0:
+		lines = insertLine(lines, lastInSect+1, name+" = "+value)
+	default:
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", name+" = "+value)
+	}
+
+	return []byte(strings.Join(lines, eol) + eol)
+}
+
+// replaceValue 替换 key = value 一行中的值，保留缩进、key、分割符两边的空格以及行尾注释
+func replaceValue(line, value string) string {
+	i := strings.IndexAny(line, "=:")
+	rest := line[i+1:]
+	lead := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+
+	comment := ""
+	if j := inlineComment(rest); j >= 0 {
+		end := j
+		for end > 0 && (rest[end-1] == ' ' || rest[end-1] == '\t') {
+			end--
+		}
+		comment = rest[end:]
+	}
+
+	return line[:i+1] + lead + value + comment
+}
+
+// inlineComment 获取值中行尾注释的位置，注释符号前面必须是空白，反引号包裹的内容不处理
+func inlineComment(s string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '`':
+			quoted = !quoted
+		case ';', '#':
+			if !quoted && i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func insertLine(lines []string, i int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[i+1:], lines[i:])
+	lines[i] = line
+
+	return lines
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const writeCfg = `; 顶部注释
+app_mode = dev
+
+[server]
+name = golang_libs_test_data
+http_port = 10000 # 端口
+Debug   :   false     ; 另一种注释
+
+# 数据库
+[DB]
+host = xxxxx
+`
+
+func TestSet(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.cfg")
+	writeFile(t, filename, writeCfg)
+	if err := os.Chmod(filename, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	sets := []struct {
+		key   string
+		value any
+	}{
+		{"server::http_port", 10001},
+		{"server::debug", true},
+		{"server::max_age", "7d"},
+		{"db::port", 3306},
+		{"note::use_libs", []string{"viper", "ini"}},
+		{"app_mode", "prod"},
+		{"db::password", "a;b"},
+	}
+	for _, s := range sets {
+		if err := Set(filename, s.key, s.value); err != nil {
+			t.Fatalf("Set(%s): %v", s.key, err)
+		}
+	}
+
+	want := "; 顶部注释\napp_mode = prod\n\n[server]\nname = golang_libs_test_data\nhttp_port = 10001 # 端口\nDebug   :   true     ; 另一种注释\nmax_age = 7d\n\n# 数据库\n[DB]\nhost = xxxxx\nport = 3306\npassword = `a;b`\n\n[note]\nuse_libs = viper,ini\n"
+	data, _ := os.ReadFile(filename)
+	if string(data) != want {
+		t.Fatalf("content = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0o640 {
+		t.Fatalf("mode = %v", info.Mode())
+	}
+
+	var c struct {
+		Server struct {
+			HttpPort int  `mapstructure:"http_port"`
+			Debug    bool `mapstructure:"debug"`
+		} `mapstructure:"server"`
+		DB struct {
+			Password string `mapstructure:"password"`
+		} `mapstructure:"db"`
+	}
+	v, err := ReadConfig(&c, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.HttpPort != 10001 || !c.Server.Debug || c.DB.Password != "a;b" || v.GetString("default::app_mode") != "prod" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+}
+
+func TestSetFileType(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "server.yaml")
+	writeFile(t, filename, "server:\n  http_port: 10000\n")
+
+	if err := Set(filename, "server.http_port", 1); !errors.Is(err, ErrFileTypeNotAllow) {
+		t.Fatalf("err = %v, want ErrFileTypeNotAllow", err)
+	}
+}
@@ -0,0 +1,274 @@
+package envload
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readDotenv parses the given dotenv files in order. ${VAR} references
+// are resolved with lookup first, so that the original environment wins
+// over dotenv files, and then with the entries parsed so far
+func readDotenv(paths []string, lookup func(string) (string, bool)) ([]iterItem, error) {
+	vars := make(map[string]string)
+
+	var items []iterItem
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parseDotenv(string(buf), vars, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("envload: %s: %w", path, err)
+		}
+		items = append(items, parsed...)
+	}
+
+	return items, nil
+}
+
+// parseDotenv parses KEY=value pairs. Lines may start with "export ",
+// single quoted values are taken literally, double quoted values support
+// escapes and may span multiple lines, and unquoted values end at a
+// " #" comment. ${VAR} and $VAR are expanded everywhere except inside
+// single quotes. Parsed entries are added to vars
+func parseDotenv(s string, vars map[string]string, lookup func(string) (string, bool)) ([]iterItem, error) {
+	p := &dotenvParser{s: s}
+	p.resolve = func(k string) string {
+		if v, ok := lookup(k); ok {
+			return v
+		}
+		return vars[k]
+	}
+
+	var items []iterItem
+	for {
+		p.skip(" \t\r\n")
+		if p.eof() {
+			return items, nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		if strings.HasPrefix(p.s[p.pos:], "export") && p.pos+6 < len(p.s) && (p.s[p.pos+6] == ' ' || p.s[p.pos+6] == '\t') {
+			p.pos += 6
+			p.skip(" \t")
+		}
+
+		key := p.key()
+		if key == "" {
+			return nil, p.errorf("invalid variable name")
+		}
+		p.skip(" \t")
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected '=' after %s", key)
+		}
+		p.pos++
+		p.skip(" \t")
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		vars[key] = value
+		items = append(items, iterItem{key: key, value: value})
+	}
+}
+
+type dotenvParser struct {
+	s       string
+	pos     int
+	resolve func(string) string
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.s[p.pos]
+}
+
+func (p *dotenvParser) skip(chars string) {
+	for !p.eof() && strings.IndexByte(chars, p.peek()) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	if i := strings.IndexByte(p.s[p.pos:], '\n'); i >= 0 {
+		p.pos += i + 1
+		return
+	}
+	p.pos = len(p.s)
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	line := strings.Count(p.s[:p.pos], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isNameByte(c byte, first bool) bool {
+	switch {
+	case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+func (p *dotenvParser) key() string {
+	start := p.pos
+	for !p.eof() && isNameByte(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *dotenvParser) value() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+
+	var (
+		value string
+		err   error
+	)
+	switch p.peek() {
+	case '\'':
+		p.pos++
+		i := strings.IndexByte(p.s[p.pos:], '\'')
+		if i < 0 {
+			return "", p.errorf("unterminated single quoted value")
+		}
+		value = p.s[p.pos : p.pos+i]
+		p.pos += i + 1
+	case '"':
+		p.pos++
+		if value, err = p.doubleQuoted(); err != nil {
+			return "", err
+		}
+	default:
+		end := strings.IndexByte(p.s[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.s) - p.pos
+		}
+		raw := p.s[p.pos : p.pos+end]
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+				raw = raw[:i]
+				break
+			}
+		}
+		if value, err = p.expand(strings.TrimSpace(raw)); err != nil {
+			return "", err
+		}
+		p.pos += end
+		return value, nil
+	}
+
+	// only whitespace or a comment may follow a quoted value
+	p.skip(" \t\r")
+	if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+		return "", p.errorf("unexpected character %q after quoted value", p.peek())
+	}
+	p.skipLine()
+
+	return value, nil
+}
+
+func (p *dotenvParser) doubleQuoted() (string, error) {
+	start := p.pos
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			p.pos++
+			if p.eof() {
+				break
+			}
+			switch e := p.peek(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+			p.pos++
+		case '$':
+			n, err := p.variable(p.s[p.pos:], &b)
+			if err != nil {
+				return "", err
+			}
+			p.pos += n
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	// report the line the value starts on
+	p.pos = start
+	return "", p.errorf("unterminated double quoted value")
+}
+
+// expand replaces ${VAR} and $VAR in an unquoted value
+func (p *dotenvParser) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		n, err := p.variable(s[i:], &b)
+		if err != nil {
+			return "", err
+		}
+		i += n
+	}
+
+	return b.String(), nil
+}
+
+// variable writes the value of the reference at the start of s to b and
+// returns the number of bytes consumed. A '$' not followed by a name is
+// kept as is
+func (p *dotenvParser) variable(s string, b *strings.Builder) (int, error) {
+	if strings.HasPrefix(s, "${") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return 0, p.errorf("unterminated variable reference")
+		}
+		b.WriteString(p.resolve(s[2:end]))
+		return end + 1, nil
+	}
+
+	n := 1
+	for n < len(s) && isNameByte(s[n], n == 1) && s[n] != '.' {
+		n++
+	}
+	if n == 1 {
+		b.WriteByte('$')
+		return 1, nil
+	}
+	b.WriteString(p.resolve(s[1:n]))
+
+	return n, nil
+}
@@ -0,0 +1,175 @@
+package envload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []iterItem
+	}{
+		{"plain", "A=1\nB = two words \n", []iterItem{{"A", "1"}, {"B", "two words"}}},
+		{"export", "export A=1\nexport\tB=2\nexporter=3\n", []iterItem{{"A", "1"}, {"B", "2"}, {"exporter", "3"}}},
+		{"empty value", "A=\nB=2", []iterItem{{"A", ""}, {"B", "2"}}},
+		{"comments", "# comment\n  # indented\nA=1 # trailing\nB=a#b\n", []iterItem{{"A", "1"}, {"B", "a#b"}}},
+		{"single quotes", `A='${HOME} \n "x" # y'`, []iterItem{{"A", `${HOME} \n "x" # y`}}},
+		{"double quotes", `A="a # b" # comment`, []iterItem{{"A", "a # b"}}},
+		{"escapes", `A="1\n2\t3\r\"\\\$HOME\x"`, []iterItem{{"A", "1\n2\t3\r\"\\$HOME\\x"}}},
+		{"multi-line double", "A=\"line1\nline2\"\nB=3", []iterItem{{"A", "line1\nline2"}, {"B", "3"}}},
+		{"multi-line single", "A='line1\nline2'\nB=3", []iterItem{{"A", "line1\nline2"}, {"B", "3"}}},
+		{"expand braces", "A=${HOME}/bin", []iterItem{{"A", "/h/bin"}}},
+		{"expand bare", `A="$HOME.d"`, []iterItem{{"A", "/h.d"}}},
+		{"expand earlier key", "A=x\nB=${A}-$A", []iterItem{{"A", "x"}, {"B", "x-x"}}},
+		{"expand missing", "A=[${NOPE}]", []iterItem{{"A", "[]"}}},
+		{"environ wins expansion", "HOME=/dotenv\nA=$HOME", []iterItem{{"HOME", "/dotenv"}, {"A", "/h"}}},
+		{"lone dollar", "A=5$ $", []iterItem{{"A", "5$ $"}}},
+		{"crlf", "A=1\r\nB=\"2\"\r\n", []iterItem{{"A", "1"}, {"B", "2"}}},
+	}
+
+	lookup := func(k string) (string, bool) {
+		if k == "HOME" {
+			return "/h", true
+		}
+		return "", false
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDotenv(tt.in, make(map[string]string), lookup)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenvError(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"missing equals", "A=1\nB\n", "line 2: expected '='"},
+		{"invalid name", "A=1\n\n1A=2\n", "line 3: invalid variable name"},
+		{"unterminated double", "A=1\nB=\"x\ny\n", "line 2: unterminated double quoted value"},
+		{"unterminated single", "A='x\n", "line 1: unterminated single quoted value"},
+		{"garbage after quote", "A=1\nB=\"x\" y\n", "line 2: unexpected character"},
+		{"unterminated reference", "A=${B\n", "line 1: unterminated variable reference"},
+	}
+
+	lookup := func(string) (string, bool) { return "", false }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDotenv(tt.in, make(map[string]string), lookup)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func writeDotenv(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func environMap(environ []string) map[string]string {
+	m := make(map[string]string)
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		m[k] = v
+	}
+	return m
+}
+
+func TestDotenvPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	first := writeDotenv(t, dir, "first.env", "ONLY_FIRST=1\nTWICE=first\nHOME=/dotenv\nFROM_ENVDIR=dotenv\n")
+	second := writeDotenv(t, dir, "second.env", "TWICE=second\nREF=${TWICE}\n")
+	envdir := writeEnvdir(t, map[string]string{"FROM_ENVDIR": "envdir", "HOME": "/envdir-home"})
+
+	l := New("HOME=/h", "ENVDIR="+envdir, "DOTENV="+first+string(os.PathListSeparator)+second)
+	ctx := context.Background()
+
+	// DOTENV is ignored without an explicit opt-in
+	if m := environMap(l.Environ(ctx, WithLoadEnvdir(false))); len(m) != 3 {
+		t.Fatalf("dotenv loaded without opt-in: %v", m)
+	}
+
+	for name, opt := range map[string]Option{
+		"WithDotenv":     WithDotenv(first, second),
+		"WithLoadDotenv": WithLoadDotenv(true),
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := &mapEnv{m: make(map[string]string)}
+			if err := l.Applpy(ctx, e, opt); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]string{
+				"ONLY_FIRST":  "1",
+				"TWICE":       "second",
+				"REF":         "second",
+				"HOME":        "/envdir-home",
+				"FROM_ENVDIR": "envdir",
+				"ENVDIR":      envdir,
+				"DOTENV":      first + string(os.PathListSeparator) + second,
+			}
+			if !reflect.DeepEqual(e.m, want) {
+				t.Fatalf("got %v, want %v", e.m, want)
+			}
+
+			m := environMap(l.Environ(ctx, opt, WithLoadEnvdir(false)))
+			if m["HOME"] != "/h" || m["TWICE"] != "second" || m["FROM_ENVDIR"] != "dotenv" {
+				t.Fatalf("environ should win over dotenv: %v", m)
+			}
+		})
+	}
+}
+
+func TestDotenvError(t *testing.T) {
+	dir := t.TempDir()
+	bad := writeDotenv(t, dir, "bad.env", "A=1\nB=\"x\n")
+
+	for name, l := range map[string]*Loader{
+		"missing": New("HOME=/h", "DOTENV="+filepath.Join(dir, "missing.env")),
+		"invalid": New("HOME=/h", "DOTENV="+bad),
+	} {
+		t.Run(name, func(t *testing.T) {
+			it := l.Iterator(context.Background(), WithLoadDotenv(true))
+			var got []string
+			for it.Next() {
+				k, _ := it.KV()
+				got = append(got, k)
+			}
+			if it.Err() == nil {
+				t.Fatal("expected an error")
+			}
+			if !reflect.DeepEqual(got, []string{"HOME", "DOTENV"}) {
+				t.Fatalf("original environment not kept: %q", got)
+			}
+		})
+	}
+
+	it := New("HOME=/h").Iterator(context.Background(), WithDotenv(bad))
+	for it.Next() {
+	}
+	if err := it.Err(); err == nil || !strings.Contains(err.Error(), "bad.env: line 2") {
+		t.Fatalf("expected error with file and line, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package envload
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,7 +26,7 @@ func New(environ ...string) *Loader {
 		environ = os.Environ()
 	}
 
-	var envdir string
+	var envdir, dotenv string
 	original := make([]iterItem, 0, len(environ))
 	for _, v := range environ {
 		i := strings.IndexByte(v, '=')
@@ -36,14 +37,18 @@ func New(environ ...string) *Loader {
 			key:   v[:i],
 			value: v[i+1:],
 		})
-		if v[:i] == "ENVDIR" {
+		switch v[:i] {
+		case "ENVDIR":
 			envdir = v[i+1:]
+		case "DOTENV":
+			dotenv = v[i+1:]
 		}
 	}
 
 	return &Loader{
 		original: original,
 		envdir:   envdir,
+		dotenv:   filepath.SplitList(dotenv),
 	}
 }
 
@@ -51,6 +56,7 @@ func (l *Loader) Restore(options ...Option) error {
 	ctx := context.Background()
 	e := SystemEnvironment()
 	var loadEnvdir bool
+	var iterOptions []Option
 	for _, o := range options {
 		switch o.Name() {
 		case ContextKey:
@@ -59,21 +65,32 @@ func (l *Loader) Restore(options ...Option) error {
 			e = o.Value().(Environment)
 		case LoadEnvdirKey:
 			loadEnvdir = o.Value().(bool)
+		case DaemontoolsKey, DotenvKey, LoadDotenvKey:
+			iterOptions = append(iterOptions, o)
 		}
 	}
 
-	return l.Applpy(ctx, e, WithLoadEnvdir(loadEnvdir))
+	return l.Applpy(ctx, e, append(iterOptions, WithLoadEnvdir(loadEnvdir))...)
 }
 
 func (l *Loader) Applpy(ctx context.Context, e Environment, options ...Option) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	e.Clearenv()
+	// collect everything first so that e is left untouched on error
+	var items []iterItem
 	iter := l.Iterator(ctx, options...)
 	for iter.Next() {
 		k, v := iter.KV()
-		e.Setenv(k, v)
+		items = append(items, iterItem{key: k, value: v})
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	e.Clearenv()
+	for _, it := range items {
+		e.Setenv(it.key, it.value)
 	}
 
 	return nil
@@ -94,15 +111,38 @@ func (l *Loader) Environ(ctx context.Context, options ...Option) []string {
 
 func (l *Loader) Iterator(ctx context.Context, options ...Option) *Iterator {
 	loadEnvdir := true
+	var daemontools, loadDotenv bool
+	var dotenv []string
 	for _, o := range options {
 		switch o.Name() {
 		case LoadEnvdirKey:
 			loadEnvdir = o.Value().(bool)
+		case DaemontoolsKey:
+			daemontools = o.Value().(bool)
+		case DotenvKey:
+			dotenv = o.Value().([]string)
+		case LoadDotenvKey:
+			loadDotenv = o.Value().(bool)
 		}
 	}
+	if dotenv == nil && loadDotenv {
+		dotenv = l.dotenv
+	}
 
 	ch := make(chan *iterItem)
 
+	// a dotenv error keeps the original environment, see Err
+	base, dotenvErr := l.withDotenv(dotenv)
+	if dotenvErr != nil {
+		base = l.original
+	}
+
+	if loadEnvdir && daemontools && l.envdir != "" {
+		iter := l.daemontoolsIterator(ctx, ch, base)
+		iter.err = errors.Join(dotenvErr, iter.err)
+		return iter
+	}
+
 	var ex chan *iterItem
 	if loadEnvdir && l.envdir != "" {
 		if fi, err := os.Stat(l.envdir); err == nil && fi.IsDir() {
@@ -139,7 +179,7 @@ func (l *Loader) Iterator(ctx context.Context, options ...Option) *Iterator {
 				}
 			}
 		}
-	}(l.original, ch, ex)
+	}(base, ch, ex)
 
 	// meanwhile, load from envdir, if available
 	if ex != nil {
@@ -172,7 +212,78 @@ func (l *Loader) Iterator(ctx context.Context, options ...Option) *Iterator {
 	}
 
 	return &Iterator{
-		ch: ch,
+		ch:  ch,
+		err: dotenvErr,
+	}
+}
+
+// withDotenv returns the variables of the dotenv files that are not in
+// the original environment, followed by the original environment
+func (l *Loader) withDotenv(paths []string) ([]iterItem, error) {
+	if len(paths) == 0 {
+		return l.original, nil
+	}
+
+	original := make(map[string]string, len(l.original))
+	for _, it := range l.original {
+		original[it.key] = it.value
+	}
+
+	items, err := readDotenv(paths, func(k string) (string, bool) {
+		v, ok := original[k]
+		return v, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a later definition of the same key wins
+	last := make(map[string]int, len(items))
+	for i, it := range items {
+		last[it.key] = i
+	}
+
+	m := make([]iterItem, 0, len(items)+len(l.original))
+	for i, it := range items {
+		if _, ok := original[it.key]; ok || last[it.key] != i {
+			continue
+		}
+		m = append(m, it)
+	}
+
+	return append(m, l.original...), nil
+}
+
+// daemontoolsIterator reads envdir up front, since an empty file has to
+// remove the variable from the original environment as well. When envdir
+// cannot be read, only base is returned and the error is reported by Err
+func (l *Loader) daemontoolsIterator(ctx context.Context, ch chan *iterItem, base []iterItem) *Iterator {
+	m := base
+	set, unset, err := readEnvdir(l.envdir)
+	if err == nil {
+		m = make([]iterItem, 0, len(base)+len(set))
+		for _, it := range base {
+			if _, ok := unset[it.key]; !ok {
+				m = append(m, it)
+			}
+		}
+		m = append(m, set...)
+	}
+
+	go func() {
+		defer close(ch)
+		for _, it := range m {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- &iterItem{key: it.key, value: it.value}:
+			}
+		}
+	}()
+
+	return &Iterator{
+		ch:  ch,
+		err: err,
 	}
 }
 
@@ -193,3 +304,11 @@ func (iter *Iterator) Next() bool {
 func (iter *Iterator) KV() (string, string) {
 	return iter.nextK, iter.nextV
 }
+
+// Err returns the error met while reading dotenv files or envdir, if any.
+// The source that failed is skipped and the iteration still yields the
+// remaining variables, so Environ never drops the
+// original environment; callers that need to know must check Err
+func (iter *Iterator) Err() error {
+	return iter.err
+}
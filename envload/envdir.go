@@ -0,0 +1,51 @@
+package envload
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readEnvdir reads dir the way daemontools envdir(8) does. It returns
+// the variables to set, in directory order, and the names of the
+// variables to remove
+func readEnvdir(dir string) ([]iterItem, map[string]struct{}, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var set []iterItem
+	unset := make(map[string]struct{})
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || entry.IsDir() {
+			continue
+		}
+		if strings.IndexByte(name, '=') >= 0 {
+			return nil, nil, fmt.Errorf("envload: invalid envdir file name %q: contains '='", name)
+		}
+
+		buf, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(buf) == 0 {
+			unset[name] = struct{}{}
+			continue
+		}
+
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			buf = buf[:i]
+		}
+		buf = bytes.TrimRight(buf, " \t")
+		buf = bytes.ReplaceAll(buf, []byte{0}, []byte{'\n'})
+
+		set = append(set, iterItem{key: name, value: string(buf)})
+	}
+
+	return set, unset, nil
+}
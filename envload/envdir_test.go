@@ -0,0 +1,115 @@
+package envload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeEnvdir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestDaemontools(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"empty file unsets", map[string]string{"HOME": ""}, []string{"KEEP=1"}},
+		{"first line only", map[string]string{"A": "first\nsecond\n"}, []string{"HOME=/h", "KEEP=1", "A=first"}},
+		{"trailing spaces and tabs", map[string]string{"A": "  value \t \n"}, []string{"HOME=/h", "KEEP=1", "A=  value"}},
+		{"nul becomes newline", map[string]string{"A": "a\x00b"}, []string{"HOME=/h", "KEEP=1", "A=a\nb"}},
+		{"dotfiles skipped", map[string]string{".hidden": "x"}, []string{"HOME=/h", "KEEP=1"}},
+		{"override", map[string]string{"HOME": "/envdir"}, []string{"HOME=/h", "KEEP=1", "HOME=/envdir"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeEnvdir(t, tt.files)
+			l := New("HOME=/h", "KEEP=1", "ENVDIR="+dir)
+
+			it := l.Iterator(context.Background(), WithDaemontools(true))
+			var got []string
+			for it.Next() {
+				k, v := it.KV()
+				if k != "ENVDIR" {
+					got = append(got, k+"="+v)
+				}
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDaemontoolsInvalidName(t *testing.T) {
+	dir := writeEnvdir(t, map[string]string{"A=B": "x", "C": "y"})
+	l := New("HOME=/h", "ENVDIR="+dir)
+
+	it := l.Iterator(context.Background(), WithDaemontools(true))
+	var got []string
+	for it.Next() {
+		k, v := it.KV()
+		got = append(got, k+"="+v)
+	}
+	if err := it.Err(); err == nil || !strings.Contains(err.Error(), "A=B") {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+	// envdir is skipped, the original environment is kept
+	if want := []string{"HOME=/h", "ENVDIR=" + dir}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if environ := l.Environ(context.Background(), WithDaemontools(true)); len(environ) != 2 {
+		t.Fatalf("Environ dropped the original environment: %q", environ)
+	}
+
+	e := &mapEnv{m: map[string]string{"OLD": "1"}}
+	if err := l.Applpy(context.Background(), e, WithDaemontools(true)); err == nil {
+		t.Fatal("expected Applpy to fail")
+	}
+	if !reflect.DeepEqual(e.m, map[string]string{"OLD": "1"}) {
+		t.Fatalf("Applpy modified the environment on error: %v", e.m)
+	}
+}
+
+func TestDaemontoolsMissingDir(t *testing.T) {
+	l := New("HOME=/h", "ENVDIR="+filepath.Join(t.TempDir(), "missing"))
+
+	it := l.Iterator(context.Background(), WithDaemontools(true))
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() == nil || n != 2 {
+		t.Fatalf("expected error and the original environment, got %v, %d entries", it.Err(), n)
+	}
+}
+
+type mapEnv struct {
+	m map[string]string
+}
+
+func (e *mapEnv) Clearenv() {
+	e.m = make(map[string]string)
+}
+
+func (e *mapEnv) Setenv(k, v string) {
+	e.m[k] = v
+}
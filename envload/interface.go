@@ -3,12 +3,14 @@ package envload
 type Loader struct {
 	original []iterItem
 	envdir   string
+	dotenv   []string
 }
 
 type Iterator struct {
 	ch    chan *iterItem
 	nextK string
 	nextV string
+	err   error
 }
 
 type iterItem struct {
@@ -32,6 +34,9 @@ const (
 	ContextKey     = "ContextKey"
 	EnvironmentKey = "EnvironmentKey"
 	LoadEnvdirKey  = "LoadEnvdirKey"
+	DaemontoolsKey = "DaemontoolsKey"
+	DotenvKey      = "DotenvKey"
+	LoadDotenvKey  = "LoadDotenvKey"
 )
 
 type option struct {
@@ -19,6 +19,39 @@ func WithLoadEnvdir(b bool) Option {
 	}
 }
 
+// WithDaemontools specifies if the contents of envdir should be read
+// with the semantics of daemontools envdir(8): an empty file removes the
+// variable, only the first line is used, trailing spaces and tabs are
+// stripped, NUL bytes become newlines and files whose name starts with
+// a dot are ignored. A file name containing '=' or a missing envdir is an
+// error: envdir is skipped and the error is reported by Iterator.Err
+func WithDaemontools(b bool) Option {
+	return &option{
+		name:  DaemontoolsKey,
+		value: b,
+	}
+}
+
+// WithDotenv specifies the dotenv files Loader should read. Variables
+// from dotenv files have the lowest precedence: the original environment
+// and envdir override them
+func WithDotenv(paths ...string) Option {
+	return &option{
+		name:  DotenvKey,
+		value: paths,
+	}
+}
+
+// WithLoadDotenv specifies if Loader should read the dotenv files listed
+// in the DOTENV variable of the original environment. WithDotenv takes
+// precedence over it
+func WithLoadDotenv(b bool) Option {
+	return &option{
+		name:  LoadDotenvKey,
+		value: b,
+	}
+}
+
 func WithContext(ctx context.Context) Option {
 	return &option{
 		name:  ContextKey,